@@ -123,6 +123,9 @@ func (l langType) FuncStart(packageName, objectName string, fn *ssa.Function, bl
 	} else {
 		//	ret += "#if (!php) private #end " // for some reason making classes private is a problem in php
 	}
+	if l.PogoComp().FnIsDynamic(fn) { // may be called via a method table, which Haxe dead code elimination cannot see
+		ret += "@:keep "
+	}
 	ret += fmt.Sprintf("class %s extends StackFrameBasis implements StackFrame { %s\n",
 		l.hc.currentfnName, l.Comment(position))
 
@@ -394,7 +397,7 @@ func (l langType) FuncStart(packageName, objectName string, fn *ssa.Function, bl
 						l.hc.pseudoNextReturnAddress--
 					}
 				case *ssa.Alloc:
-					if !in.(*ssa.Alloc).Heap { // allocate space on the stack if possible
+					if !l.PogoComp().AllocOnHeap(in.(*ssa.Alloc)) { // allocate space on the stack if possible
 						//fmt.Println("DEBUG allocate stack space for", reg, "at", position)
 						if reg != "" {
 							reg = strings.TrimSuffix(reg, "inline()") // if there is one
@@ -1757,8 +1760,11 @@ func (l langType) MakeSlice(reg string, v interface{}, errorInfo string) string
 }
 
 // TODO see http://tip.golang.org/doc/go1.2#three_index
-// TODO add third parameter when SSA code provides it to enable slice instructions to specify a capacity
-func (l langType) Slice(register string, x, lv, hv interface{}, errorInfo string) string {
+func (l langType) Slice(register string, x, lv, hv, mv interface{}, errorInfo string) string {
+	if mv != nil {
+		l.PogoComp().LogError(errorInfo, "Haxe", fmt.Errorf("haxe.Slice() - three-index slices are not implemented"))
+		return ""
+	}
 	xString := l.IndirectValue(x, errorInfo) // the target must be an array
 	if xString == "" {
 		xString = l.IndirectValue(x, errorInfo)
@@ -62,6 +62,13 @@ func (l langType) InitLang(comp *pogo.Compilation, langEnt *pogo.LanguageEntry)
 	ret.hc.funcNamesUsed = make(map[string]bool)
 	return ret
 }
+
+// JoinLang takes in the function names used by a unit's langType, as it emitted its functions on a worker.
+func (l langType) JoinLang(u pogo.Language) {
+	for name := range u.(langType).hc.funcNamesUsed {
+		l.hc.funcNamesUsed[name] = true
+	}
+}
 func (l langType) PogoComp() *pogo.Compilation {
 	return l.hc.pogoComp
 }
@@ -6,6 +6,7 @@ package asmgo
 
 import (
 	"go/ast"
+	"strings"
 
 	"golang.org/x/tools/go/ssa"
 )
@@ -66,6 +67,9 @@ var fnToVarOverloadMap = map[string]string{
 }
 
 func (l langType) FunctionOverloaded(pkg, fun string) bool {
+	if strings.HasPrefix(pkg, "_") { // the package is not in Go, but in the target language
+		return true
+	}
 	//fmt.Printf("DEBUG fn ov :%s:%s:\n", pkg, fun)
 	_, ok := fnOverloadMap[pkg+"_"+fun]
 	if ok {
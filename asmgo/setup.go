@@ -25,5 +25,7 @@ func init() {
 	langEntry.GOROOT = "/src/github.com/tardisgo/tardisgo/goroot/haxe/go1.4"
 	langEntry.TgtDir = "tardis" // TODO move to the correct directory based on a command line argument
 
+	langEntry.Sizes = &haxeStdSizes // used by the type checker, so unsafe.Sizeof etc. match the layout of Object
+
 	pogo.LanguageList = append(pogo.LanguageList, langEntry)
 }
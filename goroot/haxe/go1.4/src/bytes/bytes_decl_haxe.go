@@ -12,6 +12,8 @@
 // Package bytes contains runtime functions for the Go "bytes" standard library package when used by TARDIS Go
 package bytes
 
+import "github.com/tardisgo/tardisgo/haxe/hx"
+
 func init() { // to stop the functions being removed by dead-code-elimination
 	//if false {
 	//	IndexByte([]byte{}, 0)
@@ -37,24 +39,7 @@ func IndexByte(s []byte, c byte) int {
 // Equal returns a boolean reporting whether a == b.
 // A nil argument is equivalent to an empty slice.
 func Equal(a, b []byte) bool {
-	if a == nil {
-		if b == nil {
-			return true
-		}
-		return len(b) == 0
-	}
-	if b == nil {
-		return len(a) == 0
-	}
-	if len(a) != len(b) {
-		return false
-	}
-	for i := range a {
-		if a[i] != b[i] {
-			return false
-		}
-	}
-	return true
+	return hx.CallBool("", "Slice.bytesEqual", 2, a, b) // compares the underlying Objects directly
 } // asm_arm.s or ../runtime/asm_{386,amd64}.s
 
 //****go:noescape
@@ -63,36 +48,5 @@ func Equal(a, b []byte) bool {
 // The result will be 0 if a==b, -1 if a < b, and +1 if a > b.
 // A nil argument is equivalent to an empty slice.
 func Compare(a, b []byte) int {
-	if a == nil {
-		if b == nil {
-			return 0
-		}
-		if len(b) == 0 {
-			return 0
-		}
-		return -1
-	}
-	if b == nil {
-		if len(a) == 0 {
-			return 0
-		}
-		return 1
-	}
-	i := 0
-	for (i < len(a)) && (i < len(b)) {
-		if a[i] < b[i] {
-			return -1
-		}
-		if a[i] > b[i] {
-			return +1
-		}
-		i++
-	}
-	if len(a) == len(b) {
-		return 0
-	}
-	if len(a) < len(b) {
-		return -1
-	}
-	return +1
+	return hx.CallInt("", "Slice.bytesCompare", 2, a, b) // compares the underlying Objects directly
 } // ../runtime/noasm_arm.goc or ../runtime/asm_{386,amd64}.s
@@ -114,6 +114,31 @@ func TestEqualExhaustive(t *testing.T) {
 	}
 }
 
+// Equal and Compare work directly on the underlying array, so test slices which share one.
+func TestEqualCompareSameArray(t *testing.T) {
+	buf := []byte("abcabcabd")
+	for _, tt := range []struct {
+		a, b []byte
+		i    int
+	}{
+		{buf[0:3], buf[3:6], 0},  // equal, at different offsets
+		{buf[0:3], buf[0:3], 0},  // identical
+		{buf[0:2], buf[3:6], -1}, // shorter prefix
+		{buf[3:6], buf[0:2], 1},  // longer
+		{buf[3:6], buf[6:9], -1}, // differing last byte
+		{buf[6:9], buf[0:3], 1},
+		{buf[9:], buf[0:0], 0}, // empty, at different offsets
+		{buf[9:], nil, 0},
+	} {
+		if cmp := Compare(tt.a, tt.b); cmp != tt.i {
+			t.Errorf(`Compare(%q, %q) = %v`, tt.a, tt.b, cmp)
+		}
+		if eql := Equal(tt.a, tt.b); eql != (tt.i == 0) {
+			t.Errorf(`Equal(%q, %q) = %v`, tt.a, tt.b, eql)
+		}
+	}
+}
+
 // make sure Equal returns false for minimally different strings.  The data
 // is all zeros except for a single one in one location.
 func TestNotEqual(t *testing.T) {
@@ -369,6 +394,16 @@ func BenchmarkEqual0(b *testing.B) {
 	}
 }
 
+func BenchmarkEqualBigSubslices(b *testing.B) {
+	buf := make([]byte, 2<<20)
+	b.SetBytes(1 << 20)
+	for i := 0; i < b.N; i++ {
+		if !Equal(buf[:1<<20], buf[1<<20:]) {
+			b.Fatal("bad equal")
+		}
+	}
+}
+
 func BenchmarkEqual1(b *testing.B)           { bmEqual(b, Equal, 1) }
 func BenchmarkEqual6(b *testing.B)           { bmEqual(b, Equal, 6) }
 func BenchmarkEqual9(b *testing.B)           { bmEqual(b, Equal, 9) }
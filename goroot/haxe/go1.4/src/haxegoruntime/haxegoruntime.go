@@ -102,3 +102,22 @@ func Rune2Raw(oneRune rune) []uint { // make a string from a single rune
 	r[0] = oneRune
 	return Runes2Raw(r)
 }
+
+// runtimeError is a run-time error detected by the Haxe runtime, it satisfies the runtime.Error interface
+type runtimeError string
+
+func (e runtimeError) RuntimeError() {}
+
+func (e runtimeError) Error() string {
+	return "runtime error: " + string(e)
+}
+
+// NilDerefError returns the value that Scheduler.nilDeref() panics with, as the real runtime would
+func NilDerefError() interface{} {
+	return runtimeError("invalid memory address or nil pointer dereference")
+}
+
+// DivideByZeroError returns the value that Scheduler.divideByZero() panics with
+func DivideByZeroError() interface{} {
+	return runtimeError("integer divide by zero")
+}
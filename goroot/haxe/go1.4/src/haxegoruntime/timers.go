@@ -9,7 +9,7 @@ import (
 
 // TODO optimize to use the Timer call-back methods for the targets - flash, java, js, python
 func HaxeWait(target *int64, whileTrue *bool) {
-	fNow := hx.CallFloat("", "haxe.Timer.stamp", 0)
+	fNow := hx.CallFloat("", "Scheduler.stamp", 0)
 	fTarget := reverseNano(*target)
 	//println("DEBUG haxeWait:start now, target, *whileTrue diff = ", fNow, *target, *whileTrue, fTarget-fNow)
 	/* this "optimization" is not working, and may not be better anyway
@@ -43,7 +43,7 @@ func HaxeWait(target *int64, whileTrue *bool) {
 	*/
 	for fNow < fTarget && *whileTrue {
 		runtime.Gosched() // let other code run
-		fNow = hx.CallFloat("", "haxe.Timer.stamp", 0)
+		fNow = hx.CallFloat("", "Scheduler.stamp", 0)
 		//println("DEBUG haxeWait:loop now, target, *whileTrue diff = ", fNow, *target, *whileTrue, fTarget-fNow)
 	}
 	/*}*/
@@ -51,14 +51,14 @@ func HaxeWait(target *int64, whileTrue *bool) {
 
 // RuntimeNano returns the current value of the runtime clock in nanoseconds.
 func RuntimeNano() int64 { // function body is an Haxe addition
-	fv := hx.CallFloat("", "haxe.Timer.stamp", 0)
+	fv := hx.CallFloat("", "Scheduler.stamp", 0)
 	// cs and maybe Java have stamp values too large for int64, so set a baseline
 	if runtimeNanoBase == 0 {
 		//println("DEBUG set runtimeNanoBase")
 		runtimeNanoBase = fv
 	}
 	fv -= runtimeNanoBase
-	return int64(fv * 1000000000) // haxe.Timer.stamp is in seconds
+	return int64(fv * 1000000000) // Scheduler.stamp is in seconds
 }
 
 var runtimeNanoBase float64
@@ -15,7 +15,15 @@ import "github.com/tardisgo/tardisgo/haxe/hx"
 //	Exp(NaN) = NaN
 // Very large values overflow to 0 or +Inf.
 // Very small values underflow to 1.
-func Exp(x float64) float64 { return hx.CallFloat("", "Math.exp", 1, x) }
+func Exp(x float64) float64 {
+	switch {
+	case IsNaN(x) || IsInf(x, 1):
+		return x
+	case IsInf(x, -1):
+		return 0
+	}
+	return hx.CallFloat("", "Math.exp", 1, x)
+}
 
 func exp(x float64) float64 {
 	// The original C code, the long comment, and the constants
@@ -14,7 +14,12 @@ import "github.com/tardisgo/tardisgo/haxe/hx"
 //	Floor(±0) = ±0
 //	Floor(±Inf) = ±Inf
 //	Floor(NaN) = NaN
-func Floor(x float64) float64 { return hx.CallFloat("", "Math.ffloor", 1, x) }
+func Floor(x float64) float64 {
+	if x == 0 || IsNaN(x) || IsInf(x, 0) { // not every Haxe target keeps the sign of zero, or infinities
+		return x
+	}
+	return hx.CallFloat("", "Math.ffloor", 1, x)
+}
 
 func floor(x float64) float64 {
 	if x == 0 || IsNaN(x) || IsInf(x, 0) {
@@ -37,7 +42,15 @@ func floor(x float64) float64 {
 //	Ceil(±0) = ±0
 //	Ceil(±Inf) = ±Inf
 //	Ceil(NaN) = NaN
-func Ceil(x float64) float64 { return hx.CallFloat("", "Math.fceil", 1, x) }
+func Ceil(x float64) float64 {
+	if x == 0 || IsNaN(x) || IsInf(x, 0) {
+		return x
+	}
+	if x < 0 && x > -1 {
+		return Copysign(0, -1) // the Haxe target may give +0
+	}
+	return hx.CallFloat("", "Math.fceil", 1, x)
+}
 
 func ceil(x float64) float64 {
 	return -Floor(-x)
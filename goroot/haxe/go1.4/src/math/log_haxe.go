@@ -81,7 +81,18 @@ import "github.com/tardisgo/tardisgo/haxe/hx"
 //	Log(0) = -Inf
 //	Log(x < 0) = NaN
 //	Log(NaN) = NaN
-func Log(x float64) float64 { return hx.CallFloat("", "Math.log", 1, x) }
+func Log(x float64) float64 {
+	// special cases are handled here, as not every Haxe target follows Go
+	switch {
+	case IsNaN(x) || IsInf(x, 1):
+		return x
+	case x < 0:
+		return NaN()
+	case x == 0:
+		return Inf(-1)
+	}
+	return hx.CallFloat("", "Math.log", 1, x)
+}
 
 func log(x float64) float64 {
 	const (
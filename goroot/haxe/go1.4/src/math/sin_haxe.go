@@ -122,7 +122,12 @@ var _cos = [...]float64{
 // Special cases are:
 //	Cos(±Inf) = NaN
 //	Cos(NaN) = NaN
-func Cos(x float64) float64 { return hx.CallFloat("", "Math.cos", 1, x) }
+func Cos(x float64) float64 {
+	if IsNaN(x) || IsInf(x, 0) {
+		return NaN()
+	}
+	return hx.CallFloat("", "Math.cos", 1, x)
+}
 
 func cos(x float64) float64 {
 	const (
@@ -183,6 +188,12 @@ func Sin(x float64) float64 {
 	if runtime.GOARCH == "cs" {
 		return sin(x)
 	}
+	switch {
+	case x == 0: // keep the sign of zero
+		return x
+	case IsNaN(x) || IsInf(x, 0):
+		return NaN()
+	}
 	return hx.CallFloat("", "Math.sin", 1, x)
 }
 
@@ -93,7 +93,16 @@ import "github.com/tardisgo/tardisgo/haxe/hx"
 //	Sqrt(±0) = ±0
 //	Sqrt(x < 0) = NaN
 //	Sqrt(NaN) = NaN
-func Sqrt(x float64) float64 { return hx.CallFloat("", "Math.sqrt", 1, x) }
+func Sqrt(x float64) float64 {
+	// special cases are handled here, as not every Haxe target follows Go
+	switch {
+	case x == 0 || IsNaN(x) || IsInf(x, 1):
+		return x
+	case x < 0:
+		return NaN()
+	}
+	return hx.CallFloat("", "Math.sqrt", 1, x)
+}
 
 func sqrt(x float64) float64 {
 	// special cases
@@ -20,6 +20,10 @@ func UnzipTestFS() { // this will be overwritten by the compiler
 	println("DEBUG runtime:UnzipTestFS()")
 }
 
+func TestBench() string { // this will be overwritten by the compiler, to give the -bench pattern
+	return ""
+}
+
 // Constant values
 
 const Compiler = "gc" // this is checked by the proper runtime, so needs to be "gc"
@@ -78,8 +82,11 @@ func (r *MemProfileRecord) InUseBytes() int64   { return 0 }
 func (r *MemProfileRecord) InUseObjects() int64 { return 0 }
 func (r *MemProfileRecord) Stack() []uintptr    { return nil }
 
+// Goexit unwinds the goroutine as if it had panicked, running the deferred calls,
+// but the scheduler makes recover() return nil and ends the goroutine quietly.
 func Goexit() {
-	panic("TODO:runtime.Goexit")
+	hx.Call("", "Scheduler.goexit", 0)
+	panic("runtime.Goexit")
 }
 
 type MemStats struct {
@@ -4,6 +4,7 @@ import (
 	//fmt "fmt_dummy" // use the dummy version to improve C++ compile times
 	"fmt"
 	"os"
+	"regexp"
 	"runtime"
 	"sort"
 	"time"
@@ -47,18 +48,144 @@ type T struct {
 	common
 }
 
+// Run runs f as a subtest of t called name, waiting for it to finish, and reports whether it succeeded.
+// Subtests always run sequentially.
+func (t *T) Run(name string, f func(t *T)) bool {
+	sub := &T{common{name: t.name + "/" + name, parent: &t.common}}
+	return sub.run(f)
+}
+
+// run runs the test function on its own goroutine, so that FailNow can end it with runtime.Goexit as go test does,
+// then reports the result in the style of go test -v.
+func (t *T) run(f func(t *T)) bool {
+	fmt.Println("=== RUN  ", t.name)
+	t.done = make(chan bool)
+	go tRunner(t, f)
+	<-t.done
+	switch {
+	case t.failed:
+		fmt.Println("--- FAIL:", t.name)
+		if t.parent != nil {
+			t.parent.failed = true
+		}
+	case t.skipped:
+		fmt.Println("--- SKIP:", t.name)
+	default:
+		fmt.Println("--- PASS:", t.name)
+	}
+	return !t.failed
+}
+
+func tRunner(t *T, f func(t *T)) {
+	defer func() { t.done <- true }() // also run by runtime.Goexit
+	f(t)
+}
+
 type B struct {
 	common
-	N int
+	N        int
+	start    time.Time
+	duration time.Duration
+	timerOn  bool
+	bytes    int64
 }
 
 func (b *B) ReportAllocs()              {}
 func (b *B) RunParallel(body func(*PB)) {}
-func (b *B) SetBytes(n int64)           {}
+func (b *B) SetBytes(n int64)           { b.bytes = n }
 func (b *B) SetParallelism(p int)       {}
-func (b *B) StartTimer()                {}
-func (b *B) StopTimer()                 {}
-func (b *B) ResetTimer()                {}
+
+func (b *B) StartTimer() {
+	if !b.timerOn {
+		b.start = time.Now()
+		b.timerOn = true
+	}
+}
+
+func (b *B) StopTimer() {
+	if b.timerOn {
+		b.duration += time.Now().Sub(b.start)
+		b.timerOn = false
+	}
+}
+
+func (b *B) ResetTimer() {
+	if b.timerOn {
+		b.start = time.Now()
+	}
+	b.duration = 0
+}
+
+// runN runs the benchmark function once, with b.N set to n.
+func (b *B) runN(f func(b *B), n int) {
+	b.N = n
+	b.ResetTimer()
+	b.StartTimer()
+	f(b)
+	b.StopTimer()
+}
+
+// the time that each benchmark should run for, as the go test default
+const benchTime = time.Second
+
+// run runs the benchmark function with increasing b.N, as go test does, until it takes at least benchTime.
+func (b *B) run(f func(b *B)) BenchmarkResult {
+	var n int64 = 1 // int64, as the Haxe int is only 32 bits
+	b.runN(f, int(n))
+	for b.duration < benchTime && n < 1e9 {
+		last := n
+		if nsop := b.nsPerOp(); nsop == 0 {
+			n = 1e9
+		} else {
+			n = benchTime.Nanoseconds() / nsop
+		}
+		n = max(min(n+n/2, 100*last), last+1) // run more iterations than we think we need, but not too many
+		n = roundUp(min(n, 1e9))              // so that b.N fits in an int
+		b.runN(f, int(n))
+	}
+	return BenchmarkResult{N: b.N, T: b.duration, Bytes: b.bytes}
+}
+
+func (b *B) nsPerOp() int64 {
+	if b.N <= 0 {
+		return 0
+	}
+	return b.duration.Nanoseconds() / int64(b.N)
+}
+
+func min(x, y int64) int64 {
+	if x > y {
+		return y
+	}
+	return x
+}
+
+func max(x, y int64) int64 {
+	if x < y {
+		return y
+	}
+	return x
+}
+
+// roundUp rounds n up to a number of the form [1eX, 2eX, 3eX, 5eX].
+func roundUp(n int64) int64 {
+	var base int64 = 1
+	for base*10 <= n {
+		base *= 10
+	}
+	switch {
+	case n <= base:
+		return base
+	case n <= 2*base:
+		return 2 * base
+	case n <= 3*base:
+		return 3 * base
+	case n <= 5*base:
+		return 5 * base
+	default:
+		return 10 * base
+	}
+}
 
 type PB struct {
 	// contains filtered or unexported fields
@@ -66,51 +193,80 @@ type PB struct {
 
 func (pb *PB) Next() bool { return false }
 
-type common struct{}
+type common struct {
+	name    string
+	failed  bool
+	skipped bool
+	parent  *common   // the test that ran this one with T.Run, if any
+	done    chan bool // signalled when a test run on its own goroutine ends, nil for a benchmark
+}
+
+// failNow marks the test as failed and ends it, or if it is a benchmark, the whole program.
+func (c *common) failNow() {
+	c.failed = true
+	if c.done == nil {
+		badExit()
+	}
+	runtime.Goexit()
+}
 
 func (c *common) Error(args ...interface{}) {
 	header("Error")
 	fmt.Println(args...)
 	runtime.Breakpoint()
-	hadError = true
+	c.failed = true
 }
 
 func (c *common) Errorf(format string, args ...interface{}) {
 	header("Errorf")
 	fmt.Printf(format, args...)
 	runtime.Breakpoint()
-	hadError = true
+	c.failed = true
 }
 func (c *common) Fatalf(format string, args ...interface{}) {
 	header("Fatalf")
 	fmt.Printf(format, args...)
 	runtime.Breakpoint()
-	badExit()
+	c.failNow()
 }
 func (c *common) Logf(format string, args ...interface{}) {
 	header("Logf")
 	fmt.Printf(format, args...)
 	runtime.Breakpoint()
 }
-func (c *common) Fail()        { header("Fail"); runtime.Breakpoint(); badExit() }
-func (c *common) FailNow()     { header("FailNow"); runtime.Breakpoint(); badExit() }
-func (c *common) Failed() bool { return false }
+func (c *common) Fail()        { header("Fail"); runtime.Breakpoint(); c.failed = true }
+func (c *common) FailNow()     { header("FailNow"); runtime.Breakpoint(); c.failNow() }
+func (c *common) Failed() bool { return c.failed }
 func (c *common) Fatal(args ...interface{}) {
 	header("Fatal")
 	fmt.Println(args...)
 	runtime.Breakpoint()
-	badExit()
+	c.failNow()
+}
+func (c *common) Log(args ...interface{}) { header("Log"); fmt.Println(args...); runtime.Breakpoint() }
+func (t *common) Parallel()               {}
+func (c *common) Skip(args ...interface{}) {
+	header("Skip")
+	fmt.Println(args...)
+	runtime.Breakpoint()
+	c.skipNow()
 }
-func (c *common) Log(args ...interface{})  { header("Log"); fmt.Println(args...); runtime.Breakpoint() }
-func (t *common) Parallel()                {}
-func (c *common) Skip(args ...interface{}) { header("Skip"); fmt.Println(args...); runtime.Breakpoint() }
-func (c *common) SkipNow()                 { header("SkipNow"); runtime.Breakpoint() }
+func (c *common) SkipNow() { header("SkipNow"); runtime.Breakpoint(); c.skipNow() }
 func (c *common) Skipf(format string, args ...interface{}) {
 	header("Skipf")
 	fmt.Printf(format, args...)
 	runtime.Breakpoint()
+	c.skipNow()
+}
+func (c *common) Skipped() bool { return c.skipped }
+
+// skipNow marks the test as skipped and ends it, benchmarks carry on.
+func (c *common) skipNow() {
+	c.skipped = true
+	if c.done != nil {
+		runtime.Goexit()
+	}
 }
-func (c *common) Skipped() bool { return false }
 
 func Short() bool   { return true }
 func Verbose() bool { return false }
@@ -119,7 +275,10 @@ type InternalTest struct {
 	Name string
 	F    func(*T)
 }
-type InternalBenchmark InternalTest
+type InternalBenchmark struct {
+	Name string
+	F    func(b *B)
+}
 type InternalExample InternalTest
 
 func AllocsPerRun(runs int, f func()) (avg float64) { return 0 }
@@ -132,7 +291,11 @@ type BenchmarkResult struct {
 	MemBytes  uint64        // The total number of bytes allocated.
 }
 
-func Benchmark(f func(b *B)) BenchmarkResult { return BenchmarkResult{} }
+// Benchmark benchmarks a single function, measuring the time on the target.
+func Benchmark(f func(b *B)) BenchmarkResult {
+	var b B
+	return b.run(f)
+}
 
 func (r BenchmarkResult) AllocedBytesPerOp() int64 { return 0 }
 
@@ -140,9 +303,59 @@ func (r BenchmarkResult) AllocsPerOp() int64 { return 0 }
 
 func (r BenchmarkResult) MemString() string { return "" }
 
-func (r BenchmarkResult) NsPerOp() int64 { return 0 }
+func (r BenchmarkResult) NsPerOp() int64 {
+	if r.N <= 0 {
+		return 0
+	}
+	return r.T.Nanoseconds() / int64(r.N)
+}
 
-func (r BenchmarkResult) String() string { return "" }
+func (r BenchmarkResult) String() string {
+	return fmt.Sprintf("%8d\t%10d ns/op", r.N, r.NsPerOp())
+}
+
+// runBenchmarks runs the benchmarks whose names match pattern, in name order, reporting the timing of each.
+func runBenchmarks(pattern string, benchmarks []InternalBenchmark) {
+	names := []string{}
+	for _, f := range benchmarks {
+		names = append(names, f.Name)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		matched, err := regexp.MatchString(pattern, n) // the matchString given by the ssa test main always matches
+		if err != nil {
+			fmt.Printf("testing: invalid regexp for -bench: %s\n", err)
+			badExit()
+		}
+		if !matched {
+			continue
+		}
+		for _, f := range benchmarks {
+			if n == f.Name {
+				fmt.Printf("%s\t%s\n", n, Benchmark(f.F))
+			}
+		}
+	}
+}
+
+// M is the type passed to a TestMain function to run the tests.
+type M struct {
+	matchString func(pat, str string) (bool, error)
+	tests       []InternalTest
+	benchmarks  []InternalBenchmark
+	examples    []InternalExample
+}
+
+// MainStart is called by the test main package that ssa generates.
+func MainStart(matchString func(pat, str string) (bool, error), tests []InternalTest, benchmarks []InternalBenchmark, examples []InternalExample) *M {
+	return &M{matchString: matchString, tests: tests, benchmarks: benchmarks, examples: examples}
+}
+
+// Run runs the tests, then any benchmarks requested, exiting rather than returning the exit code.
+func (m *M) Run() int {
+	Main(m.matchString, m.tests, m.benchmarks, m.examples)
+	return 0
+}
 
 // An internal function but exported because it is cross-package; part of the implementation
 // of the "go test" command.
@@ -152,23 +365,30 @@ func Main(matchString func(pat, str string) (bool, error), tests []InternalTest,
 	if runtime.GOARCH != "" { // not running in the interpreter
 		runtime.UnzipTestFS()
 	}
-	var t T
 	names := []string{}
 	for _, f := range tests {
 		names = append(names, f.Name)
 	}
 	sort.Strings(names)
+	failed := false
 	for _, n := range names {
 		for _, f := range tests {
 			if n == f.Name {
-				println("\nExecuting test:", n)
-				f.F(&t)
+				t := &T{common{name: n}}
+				if !t.run(f.F) {
+					failed = true
+				}
 			}
 		}
 	}
-	if hadError {
+	if failed {
+		fmt.Println("FAIL")
 		badExit()
 	}
+	if pattern := runtime.TestBench(); pattern != "" {
+		runBenchmarks(pattern, benchmarks)
+	}
+	fmt.Println("PASS")
 	if runtime.GOARCH == "" { // running the interpreter
 		os.Exit(0)
 	}
@@ -176,8 +396,6 @@ func Main(matchString func(pat, str string) (bool, error), tests []InternalTest,
 	hx.Code("js", "untyped __js__('process.exit(0)');") // only works on Node
 }
 
-var hadError = false
-
 func badExit() {
 	if runtime.GOARCH == "" { // running the interpreter
 		os.Exit(1)
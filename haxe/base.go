@@ -11,6 +11,7 @@ import (
 	"go/types"
 	"reflect"
 	"sort"
+	"strconv"
 	"strings"
 	"unicode"
 
@@ -55,9 +56,26 @@ func (langType) FileStart(haxePackageName, headerText string) string {
 
 // TODO rename
 func (l langType) FileEnd() string {
+	if l.hc.langEntry.ESModule {
+		l.esModuleExports()
+	}
 	return l.haxeruntime() // this deals with the individual runtime class files
 }
 
+// esModuleExports writes the export block for the names given @:expose, which makes an ES module of the JS output,
+// when it is compiled with "-D shallow-expose" so that the exposed names are top-level vars, then appended to it.
+func (l langType) esModuleExports() {
+	names := append([]string{"Go"}, l.hc.esExports...) // the Go class is always exposed
+	sort.Strings(names[1:])
+	l.PogoComp().WriteAsAuxFile("go-exports.js", `// ES module exports, generated using the TARDIS Go tool, to use them type the command lines:
+// haxe -main tardis.Go -cp tardis -D shallow-expose -js tardis/go.js
+// cat tardis/go.js tardis/go-exports.js > tardis/go.mjs
+export {
+	`+strings.Join(names, ",\n\t")+`
+};
+`)
+}
+
 // RegisterName returns the name of an ssa.Value, a utility function in case it needs to be altered.
 func (l langType) RegisterName(val ssa.Value) string {
 	//NOTE the SSA code says that name() should not be relied on, so this code may need to alter
@@ -120,11 +138,19 @@ func (l langType) FuncStart(packageName, objectName string, fn *ssa.Function, bl
 	// because cpp & java have a problem with functions whose names are the same except for the case of the 1st letter
 	if isPublic {
 		ret += fmt.Sprintf(`#if js @:expose("Go_%s") #end `, l.LangName(packageName, objectName))
+		l.hc.esExports = append(l.hc.esExports, "Go_"+l.LangName(packageName, objectName))
 	} else {
 		//	ret += "#if (!php) private #end " // for some reason making classes private is a problem in php
 	}
+	if l.PogoComp().FnIsDynamic(fn) { // may be called via a method table, which Haxe dead code elimination cannot see
+		ret += "@:keep "
+	}
+	posComment := l.Comment(position)
+	if l.hc.langEntry.Manifest { // the position is in the manifest instead
+		posComment = ""
+	}
 	ret += fmt.Sprintf("class %s extends StackFrameBasis implements StackFrame { %s\n",
-		l.hc.currentfnName, l.Comment(position))
+		l.hc.currentfnName, posComment)
 
 	//Create the stack frame variables
 	hadBlank := false
@@ -394,7 +420,7 @@ func (l langType) FuncStart(packageName, objectName string, fn *ssa.Function, bl
 						l.hc.pseudoNextReturnAddress--
 					}
 				case *ssa.Alloc:
-					if !in.(*ssa.Alloc).Heap { // allocate space on the stack if possible
+					if !l.PogoComp().AllocOnHeap(in.(*ssa.Alloc)) { // allocate space on the stack if possible
 						//fmt.Println("DEBUG allocate stack space for", reg, "at", position)
 						if reg != "" {
 							reg = strings.TrimSuffix(reg, "inline()") // if there is one
@@ -490,8 +516,13 @@ func (l langType) whileCaseCode() string {
 
 	if l.hc.fnUsesGr {
 		ret += "\t\tswitch(_Next){\n"
-		for k, v := range l.hc.localFunctionMap {
-			ret += fmt.Sprintf("\t\t\tcase %d: retVal=%s();\n", k, v)
+		cases := make([]int, 0, len(l.hc.localFunctionMap))
+		for k := range l.hc.localFunctionMap {
+			cases = append(cases, k)
+		}
+		sort.Ints(cases) // make sure the output is always the same
+		for _, k := range cases {
+			ret += fmt.Sprintf("\t\t\tcase %d: retVal=%s();\n", k, l.hc.localFunctionMap[k])
 		}
 		ret += "\t\t}\n"
 	} else {
@@ -819,10 +850,17 @@ func (l langType) Value(v interface{}, errorInfo string) string {
 		return l.PogoComp().RegisterName(val)
 	}
 }
+
+// checkNil reports if pointers must be checked for nil before they are used,
+// so that a nil pointer dereference is a Go panic, rather than an error from the target.
+func (l langType) checkNil() bool {
+	return l.PogoComp().DebugFlag || l.hc.langEntry.NilCheck
+}
+
 func (l langType) FieldAddr(register string, v interface{}, errorInfo string) string {
 	if register != "" {
 		ptr := l.IndirectValue(v.(*ssa.FieldAddr).X, errorInfo)
-		if l.PogoComp().DebugFlag {
+		if l.checkNil() {
 			ptr = "Pointer.check(" + ptr + ")"
 		}
 		fld := v.(*ssa.FieldAddr).X.Type().Underlying().(*types.Pointer).Elem().Underlying().(*types.Struct).Field(v.(*ssa.FieldAddr).Field)
@@ -866,7 +904,7 @@ func (l langType) IndexAddr(register string, v interface{}, errorInfo string) st
 	switch v.(*ssa.IndexAddr).X.Type().Underlying().(type) {
 	case *types.Pointer:
 		ptr := l.IndirectValue(v.(*ssa.IndexAddr).X, errorInfo)
-		if l.PogoComp().DebugFlag {
+		if l.checkNil() {
 			ptr = "Pointer.check(" + ptr + ")"
 		}
 		ele := v.(*ssa.IndexAddr).X.Type().Underlying().(*types.Pointer).Elem().Underlying().(*types.Array).Elem().Underlying()
@@ -941,7 +979,7 @@ func (l langType) intTypeCoersion(t types.Type, v, errorInfo string) string {
 
 func (l langType) Store(v1, v2 interface{}, errorInfo string) string {
 	ptr := l.IndirectValue(v1, errorInfo)
-	if l.PogoComp().DebugFlag {
+	if l.checkNil() {
 		ptr = "Pointer.check(" + ptr + ")"
 	}
 	if l.is1usePtr(v1) {
@@ -1075,10 +1113,8 @@ func (l langType) Select(isSelect bool, register string, v interface{}, CommaOK
 		ret += register + ".r0= -1;\n"                                                    // the returned index if nothing is found
 
 		if len(sel.States) > 0 { // only do the logic if there are states to choose between
-			// TODO a blocking select with no states could be further optimised to stop the goroutine
-
-			// Spec requires a pseudo-random order to which item is processed
-			ret += fmt.Sprintf("{ var _states:Array<Bool> = new Array(); var _rnd=Std.random(%d);\n", len(sel.States))
+			// Spec requires a uniform pseudo-random choice between the ready states, using the scheduler's seedable source
+			ret += "{ var _states:Array<Bool> = new Array(); var _ready=0;\n"
 			for s := range sel.States {
 				switch sel.States[s].Dir {
 				case types.SendOnly:
@@ -1092,8 +1128,10 @@ func (l langType) Select(isSelect bool, register string, v interface{}, CommaOK
 					return ""
 				}
 			}
-			ret += fmt.Sprintf("for(_s in 0...%d) {var _i=(_s+_rnd)%s%d; if(_states[_i]) {%s.r0=_i; break;};}\n",
-				len(sel.States), "%", len(sel.States), register)
+			ret += fmt.Sprintf("for(_s in 0...%d) if(_states[_s]) _ready++;\n", len(sel.States))
+			ret += fmt.Sprintf("if(_ready>0) { var _rnd=Scheduler.random(_ready); "+
+				"for(_s in 0...%d) if(_states[_s]) { if(_rnd==0) {%s.r0=_s; break;}; _rnd--; }; }\n",
+				len(sel.States), register)
 			ret += fmt.Sprintf("switch(%s.r0){", register)
 			rxIdx := 0
 			for s := range sel.States {
@@ -1120,6 +1158,9 @@ func (l langType) Select(isSelect bool, register string, v interface{}, CommaOK
 		} // end only if len(sel.States)>0
 
 		if sel.Blocking {
+			if len(sel.States) == 0 { // select{} blocks forever, so stop the goroutine rather than busy-loop
+				ret += "Scheduler.park(this._goroutine);\n"
+			}
 			ret += "if(" + register + ".r0 == -1) return this;\n"
 		}
 
@@ -1170,7 +1211,7 @@ func (l langType) Ret(values []*ssa.Value, errorInfo string) string {
 }
 
 func (l langType) Panic(v1 interface{}, errorInfo string, usesGr bool) string {
-	ret := l.doCall("", nil, "Scheduler.panic(this._goroutine,"+l.IndirectValue(v1, errorInfo)+");\n", usesGr)
+	ret := l.doCall("", nil, "Scheduler.panic(this._goroutine,"+l.IndirectValue(v1, errorInfo)+");\n", usesGr, false)
 	ret += l.Ret(nil, errorInfo) // just in case we return to this point without _recoverNext being set & used
 	return ret
 }
@@ -1203,6 +1244,16 @@ func (l langType) getPackagePath(cc *ssa.CallCommon) string {
 	return pn
 }
 
+// mathIntrinsics maps the math package functions that are emitted as direct calls to the Haxe Math library,
+// via Force functions that give Go's results for NaN, infinities and signed zero.
+// Those not listed here are called as usual, so use the Go implementation in haxe/go1.4/src/math.
+var mathIntrinsics = map[string]string{
+	"math_SSqrt":  "Force.mathSqrt",
+	"math_FFloor": "Force.mathFloor",
+	"math_CCeil":  "Force.mathCeil",
+	"math_AAbs":   "Force.mathAbs",
+}
+
 func (l langType) Call(register string, cc ssa.CallCommon, args []ssa.Value, isBuiltin, isGo, isDefer, usesGr bool, fnToCall, errorInfo string) string {
 	isHaxeAPI := false
 	hashIf := ""  // #if  - only if required
@@ -1221,17 +1272,19 @@ func (l langType) Call(register string, cc ssa.CallCommon, args []ssa.Value, isB
 		}
 		switch fnToCall { // TODO handle other built-in functions?
 		case "len", "cap":
+			// _v is typed, as the argument may be a constant nil, which Haxe would not know the type of
+			vDecl := "({var _v:" + l.LangType(args[0].Type(), false, errorInfo) + "=" + l.IndirectValue(args[0], errorInfo) + ";"
 			switch args[0].Type().Underlying().(type) {
 			case *types.Chan, *types.Slice:
 				if fnToCall == "len" {
-					return register + "({var _v=" + l.IndirectValue(args[0], errorInfo) + ";_v==null?0:(_v.len());});"
+					return register + vDecl + "_v==null?0:(_v.len());});"
 				}
 				// cap
-				return register + "({var _v=" + l.IndirectValue(args[0], errorInfo) + ";_v==null?0:(_v.cap());});"
+				return register + vDecl + "_v==null?0:(_v.cap());});"
 			case *types.Array: // assume len (same as cap anyway)
 				return register + l.IndirectValue(args[0], errorInfo /*, false*/) + ".length;"
 			case *types.Map: // assume len(map)
-				return register + "({var _v=" + l.IndirectValue(args[0], errorInfo) + ";_v==null?0:_v.len();});"
+				return register + vDecl + "_v==null?0:_v.len();});"
 			case *types.Basic: // assume string as anything else would have produced an error previously
 				return register + "Force.toUTF8length(this._goroutine," + l.IndirectValue(args[0], errorInfo /*, false*/) + ");"
 			default: // TODO handle other types?
@@ -1248,10 +1301,15 @@ func (l langType) Call(register string, cc ssa.CallCommon, args []ssa.Value, isB
 				ret += ","
 			}
 			*/
-		case "delete":
-			return register + l.IndirectValue(args[0], errorInfo) + ".remove(" +
+		case "delete": // a no-op on a nil map
+			return register + "{var _v:GOmap=" + l.IndirectValue(args[0], errorInfo) + ";if(_v!=null)_v.remove(" +
 				l.serializeKey(l.IndirectValue(args[1], errorInfo),
-					l.LangType(args[1].Type().Underlying(), false, errorInfo)) + ");"
+					l.LangType(args[1].Type().Underlying(), false, errorInfo)) + ");}"
+		case "clear": // a no-op on a nil map or slice
+			if _, isMap := args[0].Type().Underlying().(*types.Map); isMap {
+				return register + "{var _v:GOmap=" + l.IndirectValue(args[0], errorInfo) + ";if(_v!=null)_v.clear();}"
+			}
+			return register + "Slice.clear(" + l.IndirectValue(args[0], errorInfo) + ");"
 		case "append":
 			return register + l.append(args, errorInfo) + ";"
 		case "copy": //TODO rework & test
@@ -1287,6 +1345,12 @@ func (l langType) Call(register string, cc ssa.CallCommon, args []ssa.Value, isB
 				return `Go_syscall_UUnzipFFSS.callFromRT(0,"` + l.hc.langEntry.TestFS + `");`
 			}
 			return ""
+		case "runtime_TTestBBench":
+			l.hc.nextReturnAddress-- //decrement to set new return address for next call generation
+			if register == "" {
+				return ""
+			}
+			return register + "=" + l.haxeStringConst(strconv.Quote(l.hc.langEntry.TestBench), errorInfo) + ";"
 		//case "math_Inf":
 		//	nextReturnAddress-- //decrement to set new return address for next call generation
 		//	return register + "=(" + l.IndirectValue(args[0], errorInfo) + ">=0?Math.POSITIVE_INFINITY:Math.NEGATIVE_INFINITY);"
@@ -1303,6 +1367,17 @@ func (l langType) Call(register string, cc ssa.CallCommon, args []ssa.Value, isB
 				return register + l.hxPseudoFuncs(fnToCall, args, errorInfo)
 			}
 
+			//
+			// math package functions with a native Haxe equivalent
+			//
+			if hxFn, found := mathIntrinsics[fnToCall]; found && !isGo && !isDefer {
+				l.hc.nextReturnAddress-- //decrement to set new return address for next call generation
+				if register != "" {
+					register += "="
+				}
+				return register + hxFn + "(" + l.IndirectValue(args[0], errorInfo) + ");"
+			}
+
 			pn := l.getPackagePath(&cc)
 			pnSplit := strings.Split(pn, "/")
 			pn = pnSplit[len(pnSplit)-1]
@@ -1329,10 +1404,10 @@ func (l langType) Call(register string, cc ssa.CallCommon, args []ssa.Value, isB
 				}
 				fnToCall = ftc // fnToCall does not now contain doubled uppercase chars
 
-				l.hc.nextReturnAddress--                // decrement to set new return address for next call generation
-				isBuiltin = true                        // pretend we are in a builtin function to avoid passing 1st param as bindings
-				isHaxeAPI = true                        // we are calling a Haxe native function
-				bits := strings.Split(fnToCall, "_47_") // split the parts of the string separated by /
+				l.hc.nextReturnAddress--                  // decrement to set new return address for next call generation
+				isBuiltin = true                          // pretend we are in a builtin function to avoid passing 1st param as bindings
+				isHaxeAPI = true                          // we are calling a Haxe native function
+				bits := strings.Split(fnToCall, "_slsh_") // split the parts of the string separated by /
 				endbit := bits[len(bits)-1]
 				foundDot := false
 				if strings.Contains(endbit, "_dot_") { // it's a dot
@@ -1519,6 +1594,10 @@ func (l langType) Call(register string, cc ssa.CallCommon, args []ssa.Value, isB
 		if arg != 0 || !isBuiltin {
 			ret += ","
 		}
+		if isBuiltin && (fnToCall == "print" || fnToCall == "println") {
+			ret += l.printArg(args[arg], errorInfo)
+			continue
+		}
 		// SAME LOGIC AS SWITCH IN INVOKE - keep in line
 		switch args[arg].Type().Underlying().(type) { // TODO this may be in need of further optimization
 		case *types.Pointer, *types.Slice, *types.Chan: // must pass a reference, not a copy
@@ -1591,14 +1670,49 @@ func (l langType) Call(register string, cc ssa.CallCommon, args []ssa.Value, isB
 	if isDefer {
 		return ret + ";\nthis.defer(Scheduler.pop(this._goroutine));"
 	}
-	return l.doCall(register, cc.Signature().Results(), ret+";\n", usesGr)
+	return l.doCall(register, cc.Signature().Results(), ret+";\n", usesGr, true)
+}
+
+// printArg gives the code to format an argument of the print or println builtins as Go does
+func (l langType) printArg(v ssa.Value, errorInfo string) string {
+	val := l.IndirectValue(v, errorInfo)
+	switch t := v.Type().Underlying().(type) {
+	case *types.Basic:
+		switch {
+		case t.Kind() == types.Bool:
+			return "Console.printBool(" + val + ")"
+		case t.Kind() == types.UnsafePointer:
+			return "Console.printPointer(" + val + ")"
+		case t.Kind() == types.Uintptr: // held as Dynamic
+			return "Console.printUint(Force.toUint32(Force.toInt(" + val + ")))"
+		case t.Kind() == types.Int64:
+			return "GOint64.toString(" + val + ")"
+		case t.Kind() == types.Uint64:
+			return "Console.printUint64(" + val + ")"
+		case t.Info()&types.IsUnsigned != 0:
+			return "Console.printUint(" + val + ")"
+		case t.Info()&types.IsFloat != 0:
+			return "Console.printFloat(Force.toFloat(" + val + "))"
+		case t.Info()&types.IsComplex != 0:
+			return "Console.printComplex(" + val + ")"
+		}
+		return val // signed integers and strings need no formatting
+	case *types.Pointer, *types.Chan, *types.Map, *types.Signature:
+		return "Console.printPointer(" + val + ")"
+	case *types.Slice:
+		return "Console.printSlice(" + val + ")"
+	case *types.Interface:
+		return "Console.printInterface(" + val + ")"
+	}
+	l.PogoComp().LogError(errorInfo, "Haxe", fmt.Errorf("haxe.printArg() - unhandled type: %s", v.Type()))
+	return val
 }
 
 func (l langType) RunDefers(usesGr bool) string {
-	return l.doCall("", nil, "this.runDefers();\n", usesGr)
+	return l.doCall("", nil, "this.runDefers();\n", usesGr, false)
 }
 
-func (l langType) doCall(register string, tuple *types.Tuple, callCode string, usesGr bool) string {
+func (l langType) doCall(register string, tuple *types.Tuple, callCode string, usesGr, isCall bool) string {
 	ret := ""
 	if register != "" {
 		ret += fmt.Sprintf("_SF%d=", -l.hc.nextReturnAddress)
@@ -1608,7 +1722,12 @@ func (l langType) doCall(register string, tuple *types.Tuple, callCode string, u
 		//await completion
 		ret += fmt.Sprintf("_Next = %d;\n", l.hc.nextReturnAddress) // where to come back to
 		l.hc.hadBlockReturn = false
-		ret += "return this;\n"
+		if isCall && l.hc.langEntry.MaxNativeDepth > 0 { // try to run the callee on the host stack, rather than yield
+			ret += "if(!Scheduler.runNative(this._goroutine,this)) return this;\n"
+			ret += "#if uselocalfunctions return null; #end\n" // otherwise drop through to the switch(_Next)
+		} else {
+			ret += "return this;\n"
+		}
 		if l.hc.fnUsesGr {
 			ret += "#if uselocalfunctions } #end"
 		} else {
@@ -1756,9 +1875,8 @@ func (l langType) MakeSlice(reg string, v interface{}, errorInfo string) string
 	return reg + "=" + newSliceCode(typeElem, initElem, capacity, length, errorInfo, itemSize) + `;`
 }
 
-// TODO see http://tip.golang.org/doc/go1.2#three_index
-// TODO add third parameter when SSA code provides it to enable slice instructions to specify a capacity
-func (l langType) Slice(register string, x, lv, hv interface{}, errorInfo string) string {
+// Slice emits x[low:high], or x[low:high:max] which also sets the capacity of the new slice
+func (l langType) Slice(register string, x, lv, hv, mv interface{}, errorInfo string) string {
 	xString := l.IndirectValue(x, errorInfo) // the target must be an array
 	if xString == "" {
 		xString = l.IndirectValue(x, errorInfo)
@@ -1768,24 +1886,48 @@ func (l langType) Slice(register string, x, lv, hv interface{}, errorInfo string
 		lvString = wrapForceToUInt(l.IndirectValue(lv, errorInfo),
 			lv.(ssa.Value).Type().Underlying().(*types.Basic).Kind())
 	}
-	hvString := "-1"
+	hvString := "" // the default depends on what is being sliced
 	if hv != nil {
 		hvString = wrapForceToUInt(l.IndirectValue(hv, errorInfo),
 			hv.(ssa.Value).Type().Underlying().(*types.Basic).Kind())
 	}
+	mvString := "" // only present for three-index slices, which can't be of strings
+	if mv != nil {
+		mvString = wrapForceToUInt(l.IndirectValue(mv, errorInfo),
+			mv.(ssa.Value).Type().Underlying().(*types.Basic).Kind())
+	}
+	// Go requires 0 <= low <= high <= max <= cap, where max is the capacity, or the length of a string
 	switch x.(ssa.Value).Type().Underlying().(type) {
 	case *types.Slice:
-		return register + "=({var _v=" + xString + `;_v==null?null:(_v.subSlice(` + lvString + `,` + hvString + `));});`
+		if hvString == "" {
+			hvString = "Slice.nullLen(_v)"
+		}
+		if mvString == "" {
+			return register + "=({var _v=" + xString + ";var _lv=" + lvString + ";var _hv=" + hvString +
+				";Slice.boundsChk(_lv,_hv,Slice.nullCap(_v));_v==null?null:(_v.subSlice(_lv,_hv));});"
+		}
+		return register + "=({var _v=" + xString + ";var _lv=" + lvString + ";var _hv=" + hvString + ";var _mv=" + mvString +
+			";Slice.boundsChk(_lv,_hv,_mv);Slice.boundsChk(_hv,_mv,Slice.nullCap(_v));" +
+			"_v==null?null:(_v.subSlice(_lv,_hv,_mv));});"
 	case *types.Pointer:
+		aLen := x.(ssa.Value).Type().Underlying().(*types.Pointer).Elem().Underlying().(*types.Array).Len()
+		if hvString == "" {
+			hvString = fmt.Sprintf("%d", aLen)
+		}
 		eleSz := "1" + arrayOffsetCalc(x.(ssa.Value).Type().Underlying().(*types.Pointer).Elem().Underlying().(*types.Array).Elem().Underlying())
-		return register + "=new Slice(" + xString + `,` + lvString + `,` + hvString + "," +
-			fmt.Sprintf("%d", x.(ssa.Value).Type().Underlying().(*types.Pointer).Elem().Underlying().(*types.Array).Len()) +
-			"," + eleSz + `);`
+		if mvString == "" {
+			return register + "=({var _lv=" + lvString + ";var _hv=" + hvString + fmt.Sprintf(";Slice.boundsChk(_lv,_hv,%d);", aLen) +
+				"new Slice(" + xString + ",_lv,_hv," + fmt.Sprintf("%d", aLen) + "," + eleSz + ");});"
+		}
+		return register + "=({var _lv=" + lvString + ";var _hv=" + hvString + ";var _mv=" + mvString +
+			fmt.Sprintf(";Slice.boundsChk(_lv,_hv,_mv);Slice.boundsChk(_hv,_mv,%d);", aLen) +
+			"new Slice(" + xString + ",_lv,_hv,_mv," + eleSz + ");});"
 	case *types.Basic: // assume a string is in need of slicing...
-		if hvString == "-1" {
+		if hvString == "" {
 			hvString = "(" + xString + ").length"
 		}
-		return register + "= ({var _lvs=" + lvString + ";(" + xString + ").substr(_lvs," + hvString + "-_lvs) ;});"
+		return register + "= ({var _lvs=" + lvString + ";var _hvs=" + hvString + ";Slice.boundsChk(_lvs,_hvs,(" + xString + ").length);(" +
+			xString + ").substr(_lvs,_hvs-_lvs) ;});"
 	default:
 		l.PogoComp().LogError(errorInfo, "Haxe",
 			fmt.Errorf("haxe.Slice() - unhandled type: %v", reflect.TypeOf(x.(ssa.Value).Type().Underlying())))
@@ -1841,15 +1983,24 @@ func (l langType) RangeCheck(x, i interface{}, length int, errorInfo string) str
 			tPtr = tPtr.(*types.Pointer).Elem().Underlying()
 		}
 		switch l.LangType(tPtr, false, errorInfo) {
-		case "Slice":
-			lStr += "" + xStr + ".length"
+		case "Slice": // a nil slice may be null, with a length of 0
+			if c, isConst := x.(*ssa.Const); isConst && c.IsNil() {
+				lStr += "0"
+			} else {
+				lStr += "(" + xStr + "==null?0:" + xStr + ".length)"
+			}
 		case "Object":
 			lStr += fmt.Sprintf("%d", tPtr.(*types.Array).Len())
 		}
-		chk = fmt.Sprintf("Scheduler.wraprangechk(%s,%s);", iStr, lStr)
+		chk = fmt.Sprintf("(%s,%s", iStr, lStr)
 	} else {
 		// length is known at compile time => an array
-		chk = fmt.Sprintf("Scheduler.wraprangechk(%s,%d);", iStr, length)
+		chk = fmt.Sprintf("(%s,%d", iStr, length)
+	}
+	if l.hc.langEntry.RangeCheckDetail { // the panic message gives the index, length and position
+		chk = "Scheduler.wraprangechkd" + chk + fmt.Sprintf(",%d);", l.PogoComp().LatestValidPosHash)
+	} else {
+		chk = "Scheduler.wraprangechk" + chk + ");"
 	}
 	ret := ""
 	_, hadIt := l.hc.rangeChecks[chk]
@@ -1881,7 +2032,7 @@ func (l langType) serializeKey(val, haxeTyp string) string { // can the key be s
 func (l langType) MapUpdate(Map, Key, Value interface{}, errorInfo string) string {
 	skey := l.serializeKey(l.IndirectValue(Key, errorInfo),
 		l.LangType(Key.(ssa.Value).Type().Underlying(), false, errorInfo))
-	ret := l.IndirectValue(Map, errorInfo) + ".set("
+	ret := "Scheduler.wrapmapchk(" + l.IndirectValue(Map, errorInfo) + ").set("
 	ret += skey + "," //+ l.IndirectValue(Key, errorInfo) + ","
 	ret += l.IndirectValue(Value, errorInfo) + ");"
 	return ret
@@ -1910,9 +2061,10 @@ func (l langType) Lookup(reg string, Map, Key interface{}, commaOk bool, errorIn
 
 	isNull := l.IndirectValue(Map, errorInfo) + ";var _ks=" + keyString + ";_map==null?"
 
-	li := l.LangType(Map.(ssa.Value).Type().Underlying().(*types.Map).Elem().Underlying(), true, errorInfo)
-	if strings.HasPrefix(li, "new ") {
-		li = "null" // no need for a full object declaration in this context
+	elem := Map.(ssa.Value).Type().Underlying().(*types.Map).Elem().Underlying()
+	li := l.LangType(elem, true, errorInfo)
+	if _, isBasic := elem.(*types.Basic); strings.HasPrefix(li, "new ") && !isBasic {
+		li = "null" // the zero value of a map, slice or channel is nil, but a complex number needs a value
 	}
 	returnValue := /*l.IndirectValue(Map, errorInfo) +*/ "_map.get(_ks)" //.val
 	//ltEle := l.LangType(Map.(ssa.Value).Type().Underlying().(*types.Map).Elem().Underlying(), false, errorInfo)
@@ -2046,7 +2198,7 @@ func (l langType) EmitInvoke(register, path string, isGo, isDefer, usesGr bool,
 		return ret + "]);\nthis.defer(Scheduler.pop(this._goroutine));"
 	}
 	cc := callCommon.(ssa.CallCommon)
-	return l.doCall(register, cc.Signature().Results(), ret+"]);", usesGr)
+	return l.doCall(register, cc.Signature().Results(), ret+"]);", usesGr, true)
 }
 
 func (l langType) deDupAssign(register, code string) string {
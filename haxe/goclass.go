@@ -60,6 +60,9 @@ func (l langType) GoClassEnd(pkg *ssa.Package) string {
 	main := "public static var doneInit:Bool=false;\n"                                                          // flag to run this routine only once
 	main += "\npublic static function init() : Void {\ndoneInit=true;\nvar gr:Int=Scheduler.makeGoroutine();\n" // first goroutine number is always 0
 	main += `if(gr!=0) throw "non-zero goroutine number in init";` + "\n"                                       // first goroutine number is always 0, NOTE using throw as panic not setup
+	if l.hc.langEntry.ScheduleSeed != 0 {
+		main += fmt.Sprintf("Scheduler.makeDeterministic(%d);\n", l.hc.langEntry.ScheduleSeed)
+	}
 
 	main += "var _sfgr=new Go_haxegoruntime_init(gr,[]).run();\n" //haxegoruntime.init() NOTE can't use .hx() to call from Haxe as that would call this fn
 	main += `Go.haxegoruntime_ZZiLLen.store_uint32('字'.length);`  // value required by haxegoruntime to know what type of strings we have
@@ -4,6 +4,8 @@
 
 package haxe
 
+import "strconv"
+
 // Runtime Haxe code for Go, which may eventually become a haxe library when the system settles down.
 // TODO All runtime class names are currently carried through if the haxe code uses "import tardis.Go;" and some are too generic,
 // others, like Int64, will overload the Haxe standard library version for some platforms, which may cause other problems.
@@ -24,31 +26,105 @@ class Console {
 			haxe.Log.trace(v);
 		#end
 	}
+	// the print and println builtins write to standard error, like Go, where the target has one
 	public static inline function println(v:Array<Dynamic>) {
 		#if ( cpp || cs || java || neko || php || python )
-			Sys.println(join(v));
+			Sys.stderr().writeString(join(v," ")+"\n");
 		#else
-			haxe.Log.trace(join(v));
+			haxe.Log.trace(join(v," "));
 		#end
 	}
 	public static inline function print(v:Array<Dynamic>) {
 		#if ( cpp || cs || java || neko || php || python )
-			Sys.print(join(v));
+			Sys.stderr().writeString(join(v,""));
 		#else
-			haxe.Log.trace(join(v));
+			haxe.Log.trace(join(v,""));
 		#end
 	}
-	static function join(v:Array<Dynamic>):String {
+	static function join(v:Array<Dynamic>,sep:String):String {
 		var s = "";
 		if(v==null) return s;
 		for (i in 0...v.length) {
-			if(v[i]==null) 
-				s += " ";
-			else
-				s += Std.string(v[i]) + " " ;
+			if(i>0)
+				s += sep;
+			if(v[i]!=null) 
+				s += Std.string(v[i]);
 		}
 		return Force.toHaxeString(s);
 	}
+	// the functions below format the arguments of print and println as the Go builtins do
+	public static function printBool(v:Bool):String {
+		return v ? "true" : "false";
+	}
+	public static function printUint(v:Int):String {
+		return GOint64.toString(GOint64.ofUInt(v));
+	}
+	public static function printUint64(v:GOint64):String {
+		if(!GOint64.isNeg(v))
+			return GOint64.toString(v);
+		var q:GOint64 = GOint64.div(v,GOint64.ofInt(10),false); // so that the rest fits in an int64
+		return GOint64.toString(q)+Std.string(GOint64.toInt(GOint64.sub(v,GOint64.mul(q,GOint64.ofInt(10)))));
+	}
+	public static function printFloat(v:Float):String { // as +d.dddddde+ddd, like the Go 1.4 runtime
+		if(Math.isNaN(v)) return "NaN";
+		if(v==Math.POSITIVE_INFINITY) return "+Inf";
+		if(v==Math.NEGATIVE_INFINITY) return "-Inf";
+		var n:Int=7; // digits printed
+		var sign:String="+";
+		var e:Int=0;
+		if(v==0) {
+			if(1/v<0) sign="-";
+		} else {
+			if(v<0) { v = -v; sign="-"; }
+			while(v>=10) { e++; v/=10; }
+			while(v<1) { e--; v*=10; }
+			var h:Float=5.0;
+			for(i in 0...n) h/=10;
+			v+=h;
+			if(v>=10) { e++; v/=10; }
+		}
+		var digits:String="";
+		for(i in 0...n) {
+			var d:Int=Math.floor(v);
+			digits += Std.string(d);
+			v-=d;
+			v*=10;
+		}
+		var es:String="+";
+		if(e<0) { e = -e; es="-"; }
+		return sign+digits.charAt(0)+"."+digits.substr(1)+"e"+es+Std.string(Std.int(e/100))+Std.string(Std.int(e/10)%10)+Std.string(e%10);
+	}
+	public static function printComplex(v:Complex):String {
+		return "("+printFloat(v.real)+printFloat(v.imag)+"i)";
+	}
+	public static function printPointer(v:Dynamic):String { // also for channels, maps and functions, whose values have no address
+		if(v==null) return "0x0";
+		if(Std.is(v,Pointer)) {
+			var p:Pointer=v;
+			return "0x"+StringTools.hex(p.obj.uniqueRef())+StringTools.hex(p.off,4);
+		}
+		var id:Null<Int>;
+		if(Type.getClass(v)==null || Std.is(v,String)) { // a value rather than an object, as in an interface, so use the value as the key
+			var k:String=Std.string(v);
+			id=valIds.get(k);
+			if(id==null) { id=++refCount; valIds.set(k,id); }
+		} else {
+			id=refIds.get(v);
+			if(id==null) { id=++refCount; refIds.set(v,id); }
+		}
+		return "0x"+StringTools.hex(id)+"0000"; // an arbitrary but unique "address"
+	}
+	static var refIds=new haxe.ds.ObjectMap<Dynamic,Int>();
+	static var valIds=new Map<String,Int>();
+	static var refCount:Int=0;
+	public static function printSlice(v:Slice):String {
+		if(v==null) return "[0/0]0x0";
+		return "["+Std.string(v.len())+"/"+Std.string(v.cap())+"]"+printPointer(v.cap()==0?null:v.baseArray);
+	}
+	public static function printInterface(v:Interface):String {
+		if(v==null) return "(0x0,0x0)";
+		return "(0x"+StringTools.hex(v.typ)+","+printPointer(v.val)+")";
+	}
 	public static function readln():Null<String> {
 		#if (cpp || cs || java || neko || php )
 			var s:String="";
@@ -118,7 +194,9 @@ class Force { // TODO maybe this should not be a separate haxe class, as no non-
 			return cast(cast(v,java.StdTypes.Int8),Int);
 		#elseif cs
 			return cast(cast(v,cs.StdTypes.Int8),Int);
-		#else
+		#elseif (js || flash)
+			return (v << 24) >> 24; // sign extend, as Int is 32 bits and >> is arithmetic
+		#else // NOTE Int may be wider (php) or narrower (neko) than 32 bits, so no shift trick
 			var r:Int = v & 0xFF;
 			if ((r & 0x80) != 0){ // it should be -ve
 				return -1 - 0xFF + r;
@@ -133,7 +211,9 @@ class Force { // TODO maybe this should not be a separate haxe class, as no non-
 			return cast(cast(v,java.StdTypes.Int16),Int);
 		#elseif cs
 			return cast(cast(v,cs.StdTypes.Int16),Int);
-		#else
+		#elseif (js || flash)
+			return (v << 16) >> 16; // sign extend, as Int is 32 bits and >> is arithmetic
+		#else // NOTE Int may be wider (php) or narrower (neko) than 32 bits, so no shift trick
 			var r:Int = v & 0xFFFF;
 			if ((r & 0x8000) != 0){ // it should be -ve
 				return -1 - 0xFFFF + r;
@@ -181,6 +261,21 @@ class Force { // TODO maybe this should not be a separate haxe class, as no non-
 					else
 						return cast(v,Int);	// default cast
 	}
+	// uintptr arithmetic is done on 32-bit Ints, as the word size is 4, but a uintptr may also hold a Pointer,
+	// converted from an unsafe.Pointer, which must be kept so that adding an offset and converting back works
+	public static function uintptrAdd(a:Dynamic,b:Dynamic):Dynamic {
+		if(Std.is(a,Pointer)) return a.addr(toInt(b));
+		if(Std.is(b,Pointer)) return b.addr(toInt(a));
+		return toUint32(toUint32(toInt(a))+toUint32(toInt(b)));
+	}
+	public static function uintptrSub(a:Dynamic,b:Dynamic):Dynamic {
+		if(Std.is(a,Pointer)) 
+			if(Std.is(b,Pointer)) 
+				return toUint32(a.off-b.off); // the distance between addresses, assumed to be in the same object
+			else
+				return a.addr(-toInt(b));
+		return toUint32(toUint32(toInt(a))-toUint32(toInt(b)));
+	}
 	public static inline function toFloat(v:Float):Float {
 		// neko target platform requires special handling because it auto-converts whole-number Float into Int without asking
 		// see: https://github.com/HaxeFoundation/haxe/issues/1282 which was marked as closed, but was not fixed as at 2013.9.6
@@ -314,7 +409,7 @@ class Force { // TODO maybe this should not be a separate haxe class, as no non-
 		var r:Int=y;
 		switch(y) {
 		case 0:
-			Scheduler.panicFromHaxe("attempt to divide integer value by 0"); 
+			Scheduler.divideByZero();
 		case -1:
 			switch (byts) {
 			case 1:
@@ -360,6 +455,24 @@ class Force { // TODO maybe this should not be a separate haxe class, as no non-
 			return x * y;
 		#end
 	}
+	// signed integer arithmetic that panics on overflow, only used if overflow trapping is on, sz is the size in bytes
+	static function ovfCheck(r:Float,sz:Int,x:Int,op:String,y:Int):Void {
+		var lim:Float = sz==1 ? 128.0 : (sz==2 ? 32768.0 : 2147483648.0);
+		if(r < -lim || r >= lim)
+			Scheduler.panicFromHaxe("integer overflow: "+Std.string(x)+op+Std.string(y)+" overflows int"+Std.string(sz*8));
+	}
+	public static function addTrap(x:Int,y:Int,sz:Int):Int {
+		ovfCheck((x:Float)+(y:Float),sz,x," + ",y);
+		return x+y;
+	}
+	public static function subTrap(x:Int,y:Int,sz:Int):Int {
+		ovfCheck((x:Float)-(y:Float),sz,x," - ",y);
+		return x-y;
+	}
+	public static function mulTrap(x:Int,y:Int,sz:Int):Int {
+		ovfCheck((x:Float)*(y:Float),sz,x," * ",y);
+		return intMul(x,y,sz);
+	}
 	public static var minusZero:Float= 1.0 / Math.NEGATIVE_INFINITY ; 
 	private static var zero:Float=0.0;
 	private static var MinFloat64:Float = -1.797693134862315708145274237317043567981e+308; // 2**1023 * (2**53 - 1) / 2**52
@@ -389,6 +502,27 @@ class Force { // TODO maybe this should not be a separate haxe class, as no non-
 		return x%y;
 	}
 
+	// the math package functions emitted as intrinsics, with Go's special cases, see haxe.mathIntrinsics
+	public static function mathSqrt(x:Float):Float {
+		if(x==0 || Math.isNaN(x) || x==Math.POSITIVE_INFINITY) return x; // Sqrt(±0) = ±0
+		if(x<0) return Math.NaN;
+		return Math.sqrt(x);
+	}
+	public static function mathFloor(x:Float):Float {
+		if(x==0 || !Math.isFinite(x)) return x; // not every Haxe target keeps the sign of zero, or infinities
+		return Math.ffloor(x);
+	}
+	public static function mathCeil(x:Float):Float {
+		if(x==0 || !Math.isFinite(x)) return x;
+		if(x<0 && x> -1) return minusZero; 
+		return Math.fceil(x);
+	}
+	public static function mathAbs(x:Float):Float {
+		if(x<0) return -x;
+		if(x==0) return zero; // Abs(-0) = +0
+		return x; // including NaN
+	}
+
 	public static inline function toUTF8length(gr:Int,s:String):Int {
 		return s.length;
 	}
@@ -539,6 +673,16 @@ class Force { // TODO maybe this should not be a separate haxe class, as no non-
 			Scheduler.panicFromHaxe("string index out of range");
 		return toUint8(c);
 	}
+	public static function stringCompare(a:String,b:String):Int { // Go byte-wise ordering: -ve if a<b, 0 if equal, +ve if a>b
+		var al:Int=a.length;
+		var bl:Int=b.length;
+		var l:Int=al<bl?al:bl;
+		for(i in 0...l) {
+			var d:Int=toUint8(a.charCodeAt(i))-toUint8(b.charCodeAt(i));
+			if(d!=0) return d;
+		}
+		return al-bl;
+	}
 	public static function stringAtOK(s:String,i:Int):Dynamic {
 		var c = s.charCodeAt(i);
 		if(c==null)
@@ -595,7 +739,17 @@ class Force { // TODO maybe this should not be a separate haxe class, as no non-
 	}
 }
 `)
-	objClass := `
+	// the bytes of each Object are held in its own Vector, or with -arena, in its part of one shared ObjectArena
+	iVecType, iVecNew, iVecBlit := "haxe.ds.Vector<Int>", "new haxe.ds.Vector<Int>(byteSize)", "haxe.ds.Vector.blit"
+	arenaCheck := ""
+	if l.hc.langEntry.Arena {
+		iVecType, iVecNew, iVecBlit = "ObjectArena", "ObjectArena.alloc(byteSize)", "ObjectArena.blit"
+		arenaCheck = `
+#if (fullunsafe || abstractobjects)
+	#error "-arena only applies to the byte store of Objects, which -D fullunsafe and -D abstractobjects do not use"
+#end`
+	}
+	objClass := arenaCheck + `
 
 // Object code
 // a single type of Go object
@@ -605,7 +759,13 @@ abstract Object (haxe.ds.Vector<Dynamic>) to haxe.ds.Vector<Dynamic> from haxe.d
 #else
 class Object { 
 #end
+	#if countobjects
+		public static var made:Int=0; // how many Objects have been made, to measure allocations
+	#end
 	public static inline function make(size:Int,?byts:haxe.io.Bytes):Object {
+		#if countobjects
+			made++;
+		#end
 		#if abstractobjects
 			var ret = new haxe.ds.Vector<Dynamic>(size);
 			if(byts!=null){ 
@@ -639,7 +799,7 @@ class Object {
 		private var arrayBuffer:js.html.ArrayBuffer;
 		private var dView:js.html.DataView;
 	#elseif !fullunsafe	// Simple! 1 address per byte, non-Int types are always on 4-byte
-		private var iVec:haxe.ds.Vector<Int>; 
+		private var iVec:` + iVecType + `; 
 	#else // fullunsafe position is to allow unsafe pointers, and therefore run slowly...
 		private var byts:haxe.io.Bytes;
 	#end
@@ -673,7 +833,7 @@ class Object {
 				for(i in 0 ... byteSize) 
 					set_uint8(i, bytes.get(i));
 		#elseif !fullunsafe
-			iVec = new haxe.ds.Vector<Int>(byteSize);
+			iVec = ` + iVecNew + `;
 			if(bytes!=null)
 				for(i in 0 ... byteSize) 
 					iVec[i] = bytes.get(i);
@@ -838,7 +998,7 @@ class Object {
 		#else //if !fullunsafe
 			if((size>>2)>0)
 				haxe.ds.Vector.blit(src.dVec4,srcPos>>2, dest.dVec4, destPos>>2, size>>2); 
-			haxe.ds.Vector.blit(src.iVec,srcPos, dest.iVec, destPos, size); 
+			` + iVecBlit + `(src.iVec,srcPos, dest.iVec, destPos, size); 
 		#end
 		} // end of: if(size>0&&src!=null) {
 	}
@@ -1001,6 +1161,9 @@ class Object {
 		var r=get(i); 
 		return r==null?"":Std.string(r);
 	}
+	public inline function get_pointer(i:Int):Pointer { 
+		return get(i); // null for a nil pointer
+	}
 	public inline function set(i:Int,v:Dynamic):Void { 
 		#if abstractobjects
 			this[i]=v;
@@ -1201,6 +1364,9 @@ class Object {
 		if(v=="") set(i,null);
 		else set(i,v); 
 	}
+	public inline function set_pointer(i:Int,v:Pointer):Void { 
+		set(i,v); 
+	}
 	private static function str(v:Dynamic):String{
 		return v==null?"nil":Std.is(v,Pointer)?v.toUniqueVal():Std.string(v);
 	}
@@ -1225,6 +1391,45 @@ class Object {
 `
 	l.PogoComp().WriteAsClass("Object", objClass)
 
+	if l.hc.langEntry.Arena {
+		l.PogoComp().WriteAsClass("ObjectArena", `
+// ObjectArena is an integer handle to the part of one flat, growable, store that holds the bytes of an Object.
+// The arena is never compacted, so the memory of Objects that are no longer used is not reused.
+abstract ObjectArena(Int) {
+	static var mem:haxe.ds.Vector<Int>=new haxe.ds.Vector<Int>(4096);
+	static var next:Int=0; // the first unused address
+	inline function new(base:Int) this=base;
+	inline function base():Int return this;
+	public static function alloc(size:Int):ObjectArena {
+		var b:Int=next;
+		next+=size;
+		if(next>mem.length || next<b) {
+			if(next<b || next>(1<<30)) // the arena is never compacted, and must not grow past the largest Vector
+				Scheduler.panicFromHaxe("out of memory: the -arena is full");
+			var l:Int=mem.length*2;
+			while(l<next) l*=2;
+			var m=new haxe.ds.Vector<Int>(l);
+			haxe.ds.Vector.blit(mem,0,m,0,b);
+			mem=m;
+		}
+		return new ObjectArena(b);
+	}
+	@:arrayAccess public inline function get(i:Int):Int {
+		return mem[this+i];
+	}
+	@:arrayAccess public inline function set(i:Int,v:Int):Int {
+		return mem[this+i]=v;
+	}
+	public static inline function blit(src:ObjectArena,srcPos:Int,dest:ObjectArena,destPos:Int,size:Int):Void {
+		haxe.ds.Vector.blit(mem,src.base()+srcPos,mem,dest.base()+destPos,size);
+	}
+	public static function used():Int { // the size of the arena in use, in addresses
+		return next;
+	}
+}
+`)
+	}
+
 	ptrClass := `
 @:keep
 class Pointer { 
@@ -1258,7 +1463,7 @@ class Pointer {
 	if l.PogoComp().DebugFlag {
 		ptrClass += `	public static function check(p:Dynamic):Pointer {
 		if(p==null) {
-			Scheduler.panicFromHaxe("nil pointer de-reference");
+			Scheduler.nilDeref();
 			return null;
 		}
 		if(Std.is(p,Pointer)) return p;
@@ -1268,7 +1473,12 @@ class Pointer {
 		return null;
 	}
 `
-	} else { // TODO null test could be removed in some future NoChecking mode maybe?
+	} else if l.hc.langEntry.NilCheck {
+		ptrClass += `	public inline static function check(p:Pointer):Pointer {
+			if(p==null) Scheduler.nilDeref();
+			return p;
+		}`
+	} else {
 		ptrClass += `	public inline static function check(p:Pointer):Pointer {
 			return p;
 		}`
@@ -1343,6 +1553,9 @@ class Pointer {
 	public #if inlinepointers inline #end function load_string():String { 
 		return obj.get_string(off);
 	}
+	public #if inlinepointers inline #end function load_pointer():Pointer { 
+		return obj.get_pointer(off);
+	}
 	public #if inlinepointers inline #end function store_object(sz:Int,v:Object):Void {
 		obj.set_object(sz,off,v);
 	}
@@ -1364,6 +1577,7 @@ class Pointer {
 	public #if inlinepointers inline #end function store_complex64(v:Complex):Void { obj.set_complex64(off,v); }
 	public #if inlinepointers inline #end function store_complex128(v:Complex):Void { obj.set_complex128(off,v); }
 	public #if inlinepointers inline #end function store_string(v:String):Void { obj.set_string(off,v); }
+	public #if inlinepointers inline #end function store_pointer(v:Pointer):Void { obj.set_pointer(off,v); }
 	public #if inlinepointers inline #end function toString(sz:Int=-1):String {
 		return " &{ "+obj.toString(off,sz)+" } ";
 	}
@@ -1389,6 +1603,13 @@ class Slice {
 		if(s==null) return 0;
 		else return s.length;
 	}
+	public static #if inlinepointers inline #end function nullCap(s:Slice):Int{
+		if(s==null) return 0;
+		else return s.cap();
+	}
+	public static #if inlinepointers inline #end function boundsChk(low:Int,high:Int,max:Int):Void{ // for x[low:high], where max is cap(x) or len(x)
+		if(low<0 || high<low || high>max) Scheduler.panicFromHaxe("slice bounds out of range");
+	}
 	public function new(fromArray:Pointer, low:Int, high:Int, ularraysz:Int, isz:Int) { 
 		baseArray = fromArray;
 		itemSize = isz;
@@ -1433,9 +1654,45 @@ class Slice {
 			byts.set(i,sl.itemAddr(i).load_uint8()); 
 		return byts;
 	}	
-	public function subSlice(low:Int, high:Int):Slice {
+	public static function bytesEqual(a:Slice,b:Slice):Bool { // fast path for bytes.Equal(), a nil Slice is the same as an empty one
+		var len:Int=nullLen(a);
+		if(len!=nullLen(b)) return false;
+		if(len==0) return true;
+		var ao:Object=a.baseArray.obj;
+		var bo:Object=b.baseArray.obj;
+		var ai:Int=a.baseArray.off+a.start; // NOTE itemSize is always 1 for []byte
+		var bi:Int=b.baseArray.off+b.start;
+		if(ao==bo && ai==bi) return true;
+		for(i in 0...len)
+			if(ao.get_uint8(ai+i)!=bo.get_uint8(bi+i)) 
+				return false;
+		return true;
+	}
+	public static function bytesCompare(a:Slice,b:Slice):Int { // fast path for bytes.Compare(), a nil Slice is the same as an empty one
+		var alen:Int=nullLen(a);
+		var blen:Int=nullLen(b);
+		var len:Int=alen<blen?alen:blen;
+		if(len>0) {
+			var ao:Object=a.baseArray.obj;
+			var bo:Object=b.baseArray.obj;
+			var ai:Int=a.baseArray.off+a.start; // NOTE itemSize is always 1 for []byte
+			var bi:Int=b.baseArray.off+b.start;
+			if(ao!=bo || ai!=bi)
+				for(i in 0...len) {
+					var ac:Int=ao.get_uint8(ai+i);
+					var bc:Int=bo.get_uint8(bi+i);
+					if(ac<bc) return -1;
+					if(ac>bc) return 1;
+				}
+		}
+		if(alen<blen) return -1;
+		if(alen>blen) return 1;
+		return 0;
+	}
+	public function subSlice(low:Int, high:Int, max:Int=-1):Slice {
 		if(high==-1) high = length; //default upper bound is the length of the current slice
-		return new Slice(baseArray,low+start,high+start,capacity,itemSize);
+		if(max==-1) return new Slice(baseArray,low+start,high+start,capacity,itemSize);
+		return new Slice(baseArray,low+start,high+start,max+start,itemSize); // x[low:high:max] limits the capacity
 	}
 	public static function append(oldEnt:Slice,newEnt:Slice):Slice{ // TODO optimize further - heavily used
 		if(oldEnt==null && newEnt==null) return null;
@@ -1463,8 +1720,21 @@ class Slice {
 			retEnt.setLength();
 			return retEnt;
 		}else{
+			// the most items that can be held, given that both the length and the Object size in bytes must fit in an Int
+			var maxCap:Float = oldEnt.itemSize<1 ? 2147483647.0 : Math.ffloor(2147483647.0/oldEnt.itemSize);
+			if((oldEnt.length:Float)+newEnt.len()>maxCap)
+				Scheduler.panicFromHaxe("growslice: cap out of range");
 			var newLen = oldEnt.length+newEnt.len();
-			var newCap = newLen+(newLen>>2); // NOTE auto-create 50pc new capacity 
+			var newCapF:Float = oldEnt.cap(); // grow as Go does, doubling small slices and adding 25pc to large ones
+			if(newCapF+newCapF<newLen) 
+				newCapF = newLen;
+			else
+				while(newCapF<newLen)
+					if(oldEnt.length<1024) newCapF += newCapF;
+					else newCapF += Math.ffloor(newCapF/4);
+			if(newCapF>maxCap) 
+				newCapF = maxCap; // growing less than usual, but still enough for newLen
+			var newCap = Std.int(newCapF);
 			var newObj:Object = Object.make(newCap*oldEnt.itemSize);
 			for(i in 0...oldEnt.length) {
 				//newObj.set_object(oldEnt.itemSize,i*oldEnt.itemSize,oldEnt.itemAddr(i).load_object(oldEnt.itemSize));
@@ -1494,15 +1764,16 @@ class Slice {
 			copySize=source.len(); 
 		if(copySize==0) return 0;
 		// Optimise not to create any temporary objects
-		if(target.baseArray==source.baseArray){ // copy within the same slice
-			if(target.start<=source.start){
+		// NOTE slices of the same underlying array may have different Pointer instances, so compare the Objects
+		if(target.baseArray.obj==source.baseArray.obj){ // copy within the same underlying array, like memmove
+			if(target.baseArray.off+target.itemOff(0)<=source.baseArray.off+source.itemOff(0)){ // copy front-to-back
 				for(i in 0...copySize){
 					//target.itemAddr(i).store_object(target.itemSize,source.itemAddr(i).load_object(target.itemSize));
 					Object.objBlit(source.baseArray.obj,source.itemOff(i)+source.baseArray.off,
 						target.baseArray.obj,target.itemOff(i)+target.baseArray.off,
 						target.itemSize);
 				}
-			}else{
+			}else{ // copy back-to-front, so that source items are read before they are overwritten
 				var i = copySize-1;
 				while(i>=0){
 					//target.itemAddr(i).store_object(target.itemSize,source.itemAddr(i).load_object(target.itemSize));
@@ -1523,6 +1794,12 @@ class Slice {
 		target.setLength();
 		return copySize;
 	}
+	public static function clear(target:Slice){ // for the clear() builtin, zero the items up to len
+		if(target==null) return;
+		var zero:Object=Object.make(target.itemSize);
+		for(i in 0...target.len())
+			Object.objBlit(zero,0,target.baseArray.obj,target.itemOff(i)+target.baseArray.off,target.itemSize);
+	}
 	public function param(idx:Int):Dynamic { // special case for .hx pseudo functions
 		var ptr=itemAddr(idx);
 		var ret=ptr.load();
@@ -1725,14 +2002,13 @@ class Interface { // "interface" is a keyword in PHP but solved using compiler f
 			}
 	}
 	public static function isEqual(a:Interface,b:Interface):Bool {		
-	// TODO ensure this very wide definition of equality is OK 
 	// TODO is another special case required for Slice/Object?
 		if(a==null) 
 			if(b==null) return true;
 			else 		return false;
 		if(b==null)		
 			return false;
-		if(! (TypeInfo.isIdentical(a.typ,b.typ)||TypeAssign.isAssignableTo(a.typ,b.typ)||TypeAssign.isAssignableTo(b.typ,a.typ)) ) 
+		if(!TypeInfo.isIdentical(a.typ,b.typ)) // dynamic types must be identical, being assignable is not enough
 			return false;	
 		return Force.isEqualDynamic(a.val,b.val);
 	}			
@@ -2085,7 +2361,7 @@ public static inline function and(x:HaxeInt64abs,y:HaxeInt64abs):HaxeInt64abs {
 }
 private static function checkDiv(x:HaxeInt64abs,y:HaxeInt64abs,isSigned:Bool):HaxeInt64abs {
 	if(HaxeInt64Typedef.isZero(y))
-		Scheduler.panicFromHaxe( "attempt to divide 64-bit value by 0"); 
+		Scheduler.divideByZero();
 	if(isSigned && (HaxeInt64Typedef.compare(y,HaxeInt64Typedef.ofInt(-1))==0) && (HaxeInt64Typedef.compare(x,HaxeInt64Typedef.make(0x80000000,0))==0) ) 
 	{
 		//trace("checkDiv 64-bit special case");
@@ -2175,6 +2451,27 @@ public static inline function sub(x:HaxeInt64abs,y:HaxeInt64abs):HaxeInt64abs {
 public static inline function xor(x:HaxeInt64abs,y:HaxeInt64abs):HaxeInt64abs {
 	return new HaxeInt64abs(HaxeInt64Typedef.xor(x,y));
 }
+// signed 64-bit arithmetic that panics on overflow, only used if overflow trapping is on
+static function ovf(x:HaxeInt64abs,op:String,y:HaxeInt64abs):Void {
+	Scheduler.panicFromHaxe("integer overflow: "+toStr(x)+op+toStr(y)+" overflows int64");
+}
+public static function addTrap(x:HaxeInt64abs,y:HaxeInt64abs):HaxeInt64abs {
+	var r=add(x,y);
+	if(isNeg(x)==isNeg(y) && isNeg(r)!=isNeg(x)) ovf(x," + ",y);
+	return r;
+}
+public static function subTrap(x:HaxeInt64abs,y:HaxeInt64abs):HaxeInt64abs {
+	var r=sub(x,y);
+	if(isNeg(x)!=isNeg(y) && isNeg(r)!=isNeg(x)) ovf(x," - ",y);
+	return r;
+}
+public static function mulTrap(x:HaxeInt64abs,y:HaxeInt64abs):HaxeInt64abs {
+	var r=mul(x,y);
+	if(!isZero(x) && (compare(div(r,x,true),y)!=0 || // MinInt64 / -1 gives MinInt64, so check that case explicitly
+		(compare(x,ofInt(-1))==0 && compare(y,make(0x80000000,0))==0)))
+		ovf(x," * ",y);
+	return r;
+}
 public static inline function compare(x:HaxeInt64abs,y:HaxeInt64abs):Int {
 	return HaxeInt64Typedef.compare(x,y);
 }
@@ -2753,16 +3050,21 @@ function setDebugVar(name:String,value:Dynamic):Void;
 @:cppFileCode('extern "C" int tardisgo_timereventhandler(int rl) { tardis::Scheduler_obj::runLimit=rl; tardis::Scheduler_obj::timerEventHandler(0); return 0; }')
 
 @:keep
-class Scheduler { // NOTE this code requires a single-thread, as there is no locking TODO detect deadlocks
+class Scheduler { // NOTE this code requires a single-thread, as there is no locking TODO detect all deadlocks, not just select{}
 // public
 public static var doneInit:Bool=false; // flag to limit go-routines to 1 during the init() processing phase
 // private
 static var grStacks:Array<Array<StackFrame>>=new Array<Array<StackFrame>>(); 
 static var grInPanic:Array<Bool>=new Array<Bool>();
 static var grPanicMsg:Array<Interface>=new Array<Interface>();
+static var grParked:Array<Bool>=new Array<Bool>(); // goroutines blocked forever, by an empty select{}
+static var grGoexit:Array<Bool>=new Array<Bool>(); // goroutines unwinding because of runtime.Goexit()
 static var panicStackDump:String="";
 static var entryCount:Int=0; // this to be able to monitor the re-entrys into this routine for debug
 static var currentGR:Int=0; // the current goroutine, used by Scheduler.panicFromHaxe(), NOTE this requires a single thread
+static var rndState:Float=0; // Park-Miller generator state used by select, 0 until seeded
+static var virtualClock:Float=-1; // seconds, only used when deterministic, otherwise -1
+static inline var quantum:Float=0.001; // the seconds that the virtual clock advances for each run through the goroutines
 
 // if the scheduler is being run from a timer, this is where it comes to
 public static var runLimit:Int=0;
@@ -2823,20 +3125,28 @@ public static function runAll() { // this must be re-entrant, in order to allow
 			return; // nothing to do...
 		}
 	} else { // run goroutine zero
-		runOne(0,entryCount,thisStack,thisStackLen);
+		if(!grParked[0])
+			runOne(0,entryCount,thisStack,thisStackLen);
 	}
 
 	if(doneInit && entryCount==1 ) {	 // don't run extra goroutines when we are re-entrant or have not finished initialistion
 									     // NOTE this means that Haxe->Go->Haxe->Go code cannot run goroutines 
+		if(virtualClock>=0) 
+			virtualClock+=quantum;
 		var grStacksLen=grStacks.length;
 		for(cg in 1...grStacksLen) { // length may grow during a run through, NOTE goroutine 0 not run again
 			thisStack=grStacks[cg];
 			thisStackLen=thisStack.length;
-			if(thisStackLen>0) {
+			if(thisStackLen>0 && !grParked[cg]) {
 				runOne(cg,entryCount,thisStack,thisStackLen);
 			}
 		}
 
+		if(grParked[0] && allParked()) {
+			Console.naclWrite("fatal error: all goroutines are asleep - deadlock!\n"+stackDump());
+			throw "Go deadlock";
+		}
+
 		// prune the list of goroutines only at the end (goroutine numbers are in the stack frames, so can't be altered) 
 		grStacksLen=grStacks.length;// there may be more goroutines than we started with
 		if(grStacksLen>1) // we must always have goroutine 0
@@ -2848,6 +3158,30 @@ public static function runAll() { // this must be re-entrant, in order to allow
 	#end
 	entryCount--;
 }
+static function allParked():Bool { // true if no goroutine can ever run again
+	for(gr in 0...grStacks.length)
+		if(grStacks[gr].length>0 && !grParked[gr])
+			return false;
+	return true;
+}
+public static function park(gr:Int){ // used by select{}, the goroutine will never be run again
+	if(gr>=grStacks.length||gr<0)
+		throw "Scheduler.park() invalid goroutine";
+	grParked[gr]=true;
+}
+static var nativeDepth:Int=0; // how many calls deep runNative() has gone on the host stack
+public static function runNative(gr:Int,caller:StackFrame):Bool { // run the function just called by caller on the host stack, if not too deep
+	if(nativeDepth>=`+strconv.Itoa(l.hc.langEntry.MaxNativeDepth)+`) 
+		return false; // so the caller yields, and the scheduler runs the callee from the goroutine's own stack
+	var stk=grStacks[gr];
+	var sf=stk[stk.length-1];
+	if(sf==caller) 
+		return false; // nothing was called
+	nativeDepth++;
+	sf.run();
+	nativeDepth--;
+	return !sf._incomplete && !grInPanic[gr]; // if the callee did not complete, or panicked, the caller must yield
+}
 static inline function runOne(gr:Int,entryCount:Int,thisStack:Array<StackFrame>,thisStackLen:Int){ // called from above to call individual goroutines TODO: Review for multi-threading
 	if(grInPanic[gr]) {
 		if(entryCount!=1) { // we are in re-entrant code, so we can't panic again, as this may be part of the panic handling...
@@ -2856,6 +3190,12 @@ static inline function runOne(gr:Int,entryCount:Int,thisStack:Array<StackFrame>,
 		} else {
 			while(grInPanic[gr]){
 				if(grStacks[gr].length==0){
+					if(grGoexit[gr]) { // all of the deferred calls have been run, so the goroutine has ended
+						grInPanic[gr]=false;
+						grPanicMsg[gr]=null;
+						grGoexit[gr]=false;
+						break;
+					}
 					 Console.naclWrite("Panic in goroutine "+gr+"\n"+panicStackDump); // use stored stack dump
 					 throw "Go panic";
 				} else {
@@ -2885,6 +3225,13 @@ static inline function runOne(gr:Int,entryCount:Int,thisStack:Array<StackFrame>,
 				}
 			}
 		}
+	} else if(entryCount==1) {
+		try {
+			run1a(gr,thisStack,thisStackLen);
+		} catch(c:Dynamic) {
+			if(c!=runtimePanic || !grInPanic[gr]) throw c;
+			nativeDepth=0; // the host stack has been unwound to here, the deferred calls are run as above next time
+		}
 	} else {
 		run1a(gr,thisStack,thisStackLen);
 	}
@@ -2902,12 +3249,16 @@ public static function makeGoroutine():Int {
 		{
 			grInPanic[r]=false;
 			grPanicMsg[r]=null;
+			grParked[r]=false;
+			grGoexit[r]=false;
 			return r;	// reuse a previous goroutine number if possible
 		}
 	var l:Int=grStacks.length;
 	grStacks[l]=new Array<StackFrame>(); 
 	grInPanic[l]=false;
 	grPanicMsg[l]=null;
+	grParked[l]=false;
+	grGoexit[l]=false;
 	return l;
 }
 public static inline function pop(gr:Int):StackFrame {
@@ -3020,7 +3371,7 @@ public static function panic(gr:Int,err:Interface){
 public static function recover(gr:Int):Interface{
 	if(gr>=grStacks.length||gr<0)
 		throw "Scheduler.recover() invalid goroutine";
-	if(grInPanic[gr]==false)
+	if(grInPanic[gr]==false || grGoexit[gr]) // Goexit can't be recovered
 		return null;
 	#if godebug
 		trace("GODEBUG: recover in goroutine "+Std.string(gr)+" message: "+grPanicMsg[gr]);
@@ -3033,6 +3384,28 @@ public static function recover(gr:Int):Interface{
 	grPanicMsg[gr]=null;
 	return t;
 }
+public static function goexit() { // called by runtime.Goexit(), just before it panics
+	grGoexit[currentGR]=true;
+}
+public static function makeDeterministic(seed:Int) { // see pogo.LanguageEntry.ScheduleSeed
+	// goroutines are always run in turn, so the run-to-run variations come from select and the clock
+	seedRandom(seed);
+	virtualClock=0;
+}
+public static function stamp():Float { // the runtime clock in seconds, as haxe.Timer.stamp()
+	if(virtualClock>=0) 
+		return virtualClock;
+	return haxe.Timer.stamp();
+}
+public static function seedRandom(seed:Int) { // so that the choices made by select can be reproduced
+	rndState=1+Math.abs(seed)%2147483646;
+}
+public static function random(n:Int):Int { // pseudo-random in 0...n, Float arithmetic so that it is exact on every target
+	if(rndState==0) 
+		seedRandom(Std.random(2147483646));
+	rndState=(rndState*16807)%2147483647;
+	return Std.int(rndState%n);
+}
 public static function panicFromHaxe(err:String) { 
 	if(currentGR>=grStacks.length||currentGR<0) 
 		// if current goroutine is -ve, or out of range, always panics in goroutine 0
@@ -3042,6 +3415,19 @@ public static function panicFromHaxe(err:String) {
 	Console.naclWrite(panicStackDump); 
 	throw "Haxe panic"; // NOTE can't be recovered!
 }
+static var runtimePanic:String="Go runtime panic"; // thrown to unwind the host stack to runOne(), after a panic that can be recovered
+public static function nilDeref() {
+	if(currentGR>=grStacks.length||currentGR<0) 
+		panicFromHaxe("invalid memory address or nil pointer dereference");
+	panic(currentGR,Go_haxegoruntime_NNilDDerefEError.callFromRT(currentGR)); // a runtime.Error, as in Go
+	throw runtimePanic;
+}
+public static function divideByZero() {
+	if(currentGR>=grStacks.length||currentGR<0) 
+		panicFromHaxe("integer divide by zero");
+	panic(currentGR,Go_haxegoruntime_DDivideBByZZeroEError.callFromRT(currentGR)); // a runtime.Error, as in Go
+	throw runtimePanic;
+}
 public static function bbi() {
 	panicFromHaxe("bad block ID (internal phi error)");
 }
@@ -3049,12 +3435,18 @@ public static function ioor() {
 	panicFromHaxe("index out of range");
 }
 public static function htc(c:Dynamic,pos:Int) {
+	if(c==runtimePanic) 
+		throw c; // a Go panic, which the scheduler unwinds
 	panicFromHaxe("Haxe try-catch exception <"+Std.string(c)+"> position "+Std.string(pos)+
 		" at or before: "+Go.CPos(pos));
 }
 public static #if inlinepointers inline #end function wraprangechk(val:Int,sz:Int) {
 	if((val<0)||(val>=sz)) ioor();
 }
+public static function wraprangechkd(val:Int,sz:Int,pos:Int) { // with details of the failure, see pogo.LanguageEntry.RangeCheckDetail
+	if((val<0)||(val>=sz)) 
+		panicFromHaxe("index out of range ["+Std.string(val)+"] with length "+Std.string(sz)+" at or before: "+Go.CPos(pos));
+}
 public static function unt():Dynamic {
 		panicFromHaxe("nil interface target for method");	
 		return null;
@@ -3066,6 +3458,13 @@ public static function wrapnilchk(p:Pointer):Pointer {
 	if(p==null) unp();
 	return p;
 }
+static function anm() {
+		panicFromHaxe("assignment to entry in nil map");	
+}
+public static #if inlinepointers inline #end function wrapmapchk(m:GOmap):GOmap {
+	if(m==null) anm();
+	return m;
+}
 }
 `)
 	l.PogoComp().WriteAsClass("GOmap", `
@@ -3170,6 +3569,10 @@ class GOmap {
 		baseMap.remove(s);
 	}
 
+	public function clear(){ // for the clear() builtin, the map itself remains, so it may be used again
+		baseMap = new Map<String,{key:Dynamic,val:Dynamic}>();
+	}
+
 	public function len():Int {
 		var _l:Int=0;
 		var _it=baseMap.iterator();
@@ -3199,17 +3602,15 @@ class GOmapRange {
 		m=mv;
 	}
 
+	// the keys are a snapshot taken when the range started, so deleting any entry during the range is safe
 	public function next():{r0:Bool,r1:Dynamic,r2:Dynamic} {
-		var _hn:Bool=k.length>0;
-		if(_hn){
+		while(k.length>0){
 			var _nxt=k.pop();
-			if(m.baseMap.exists(_nxt))
-				return {r0:true,r1:m.baseMap.get(_nxt).key,r2:m.baseMap.get(_nxt).val};
-			else
-				return next(); // recurse if this key is not found (deleted in-between?)
-		}else{
-			return {r0:false,r1:m.kz,r2:m.vz};
+			var _ent=m.baseMap.get(_nxt);
+			if(_ent!=null) // skip keys deleted since the range started, without recursion as there may be many
+				return {r0:true,r1:_ent.key,r2:_ent.val};
 		}
+		return {r0:false,r1:m.kz,r2:m.vz};
 	}
 }
 `)
@@ -3218,23 +3619,67 @@ class GOmapRange {
 class GOstringRange {
 	private var g:Int;
 	private var k:Int;
-	private var v:Slice;
+	private var v:String;
 
 	public function new(gr:Int,s:String){
 		g=gr;
 		k=0;
-		v=Force.toUTF8slice(gr,s);
+		v=s;
 	}
 
+	private inline function byteAt(i:Int):Int {
+		#if (js || php || neko ) // nullable targets
+			var t:Null<Int>=v.charCodeAt(i);
+			if(t==null) t=0;
+			return t&0xff;
+		#else
+			return v.charCodeAt(i)&0xff;
+		#end
+	}
+
+	// a continuation byte at i, or -1 if there is none
+	private inline function contAt(i:Int):Int {
+		if(i>=v.length) return -1;
+		var c=byteAt(i);
+		return (c&0xC0)==0x80 ? c&0x3F : -1;
+	}
+
+	// decode the UTF-8 rune starting at byte k, as utf8.DecodeRuneInString does,
+	// invalid encodings give U+FFFD and a width of 1
 	public function next():{r0:Bool,r1:Int,r2:Int} {
 		var _thisK:Int=k;
-		if(k>=v.len())
+		if(v==null || k>=v.length)
 			return {r0:false,r1:0,r2:0};
-		else {
-			var _dr:{r0:Int,r1:Int}=Go_unicode_slsh_utf8_DDecodeRRune.callFromRT(g,v.subSlice(_thisK,-1));
-			k+=_dr.r1;
-			return {r0:true,r1:_thisK,r2:_dr.r0};
+		var r:Int=0xFFFD;
+		var w:Int=1;
+		var c0=byteAt(k);
+		if(c0<0x80) {
+			r=c0;
+		} else if(c0>=0xC0 && c0<0xF8) {
+			var c1=contAt(k+1);
+			if(c1>=0) {
+				if(c0<0xE0) {
+					var r2=((c0&0x1F)<<6)|c1;
+					if(r2>0x7F) { r=r2; w=2; }
+				} else {
+					var c2=contAt(k+2);
+					if(c2>=0) {
+						if(c0<0xF0) {
+							var r3=((c0&0x0F)<<12)|(c1<<6)|c2;
+							if(r3>0x7FF && (r3<0xD800 || r3>0xDFFF)) { r=r3; w=3; }
+						} else {
+							var c3=contAt(k+3);
+							if(c3>=0) {
+								var r4=((c0&0x07)<<18)|(c1<<12)|(c2<<6)|c3;
+								if(r4>0xFFFF && r4<=0x10FFFF) { r=r4; w=4; }
+							}
+						}
+					}
+				}
+			}
 		}
+		k+=w;
+		return {r0:true,r1:_thisK,r2:r};
 	}
 }
 
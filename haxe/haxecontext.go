@@ -43,6 +43,8 @@ type haxeContext struct {
 
 	tempVarList []regToFree
 
+	esExports []string // the names exposed to JS, to list as ES module exports
+
 	typesByID []types.Type
 	pte       typeutil.Map
 	pteKeys   []types.Type
@@ -62,6 +64,14 @@ func (l langType) InitLang(comp *pogo.Compilation, langEnt *pogo.LanguageEntry)
 	ret.hc.funcNamesUsed = make(map[string]bool)
 	return ret
 }
+
+// JoinLang takes in the function names used, and ES module exports, recorded by a unit's langType, as it emitted its functions on a worker.
+func (l langType) JoinLang(u pogo.Language) {
+	for name := range u.(langType).hc.funcNamesUsed {
+		l.hc.funcNamesUsed[name] = true
+	}
+	l.hc.esExports = append(l.hc.esExports, u.(langType).hc.esExports...)
+}
 func (l langType) PogoComp() *pogo.Compilation {
 	return l.hc.pogoComp
 }
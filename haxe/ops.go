@@ -6,7 +6,9 @@ package haxe
 
 import (
 	"fmt"
+	"go/constant"
 	"go/types"
+	"math"
 
 	"golang.org/x/tools/go/ssa"
 )
@@ -49,7 +51,7 @@ func (l langType) codeUnOp(regTyp types.Type, op string, v interface{}, CommaOK
 			}
 			return oup.obj + ".get" + loadStoreSuffix(goTyp, true) + oup.off + ")"
 		}
-		if l.PogoComp().DebugFlag {
+		if l.checkNil() {
 			iVal = "Pointer.check(" + iVal + ")"
 		}
 		return iVal + ".load" + loadStoreSuffix(goTyp, false) + ")" + fmt.Sprintf("/* %v */ ", goTyp)
@@ -61,6 +63,16 @@ func (l langType) codeUnOp(regTyp types.Type, op string, v interface{}, CommaOK
 		fallthrough
 	default:
 		if useInt64 {
+			if l.hc.langEntry.NativeInt64 {
+				switch op {
+				case "-", "^":
+					if op == "^" {
+						op = "~" // Haxe has a different operator for bit-wise complement
+					}
+					return l.intTypeCoersion(v.(ssa.Value).Type().Underlying(),
+						"("+op+"("+l.IndirectValue(v, errorInfo)+":haxe.Int64))", errorInfo)
+				}
+			}
 			switch op { // roughly in the order of the GOint64 api spec
 			case "-":
 				return l.intTypeCoersion(v.(ssa.Value).Type().Underlying(),
@@ -77,8 +89,6 @@ func (l langType) codeUnOp(regTyp types.Type, op string, v interface{}, CommaOK
 			switch v.(ssa.Value).Type().Underlying().(*types.Basic).Kind() {
 			case types.Uintptr: // although held as Dynamic, uintpointers are integers when doing calculations
 				valStr = "Force.toUint32(Force.toInt(" + valStr + "))"
-			case types.Float32:
-				valStr = "Force.toFloat32(" + valStr + ")"
 			case types.Float64:
 				valStr = "Force.toFloat(" + valStr + ")"
 			}
@@ -162,13 +172,12 @@ func (l langType) codeBinOp(regTyp types.Type, op string, v1, v2 interface{}, er
 		}
 
 	} else if v1LangType == "String" {
-		//switch op {
-		//case ">", "<", "<=", ">=":
-		//	return "(Go_haxegoruntime_StringCompare.callFromRT(this._goroutine," + v1string + "," + v2string +
-		//		")" + op + "0)"
-		//default:
-		return "(" + v1string + op + v2string + ")"
-		//}
+		switch op {
+		case ">", "<", "<=", ">=": // Go orders strings byte-wise, which the target's String ordering may not
+			return "(Force.stringCompare(" + v1string + "," + v2string + ")" + op + "0)"
+		default:
+			return "(" + v1string + op + v2string + ")"
+		}
 
 	} else if v1LangType == "Interface" {
 		switch op {
@@ -211,13 +220,24 @@ func (l langType) codeBinOp(regTyp types.Type, op string, v1, v2 interface{}, er
 			}
 
 			if op == "<<" || op == ">>" {
-				v2string = wrapForceToUInt(v2string, v2.(ssa.Value).Type().Underlying().(*types.Basic).Kind())
+				v2string = shiftCount(v2, v2string)
+			}
+			trap := "" // signed overflow only panics if trapping is on
+			if l.trapOverflowSize(v1.(ssa.Value).Type()) > 0 {
+				trap = "Trap"
+			}
+			if native := l.nativeInt64Op(op, v1, v2, v1string, v2string, trap != ""); native != "" {
+				switch op {
+				case "==", "!=", "<", ">", "<=", ">=":
+					return native
+				}
+				return l.intTypeCoersion(v1.(ssa.Value).Type().Underlying(), native, errorInfo)
 			}
 
 			switch op { // roughly in the order of the GOint64 api spec
 			case "+":
 				ret = l.intTypeCoersion(v1.(ssa.Value).Type().Underlying(),
-					"GOint64.add("+v1string+","+v2string+")", errorInfo)
+					"GOint64.add"+trap+"("+v1string+","+v2string+")", errorInfo)
 			case "&":
 				ret = l.intTypeCoersion(v1.(ssa.Value).Type().Underlying(),
 					"GOint64.and("+v1string+","+v2string+")", errorInfo)
@@ -229,7 +249,7 @@ func (l langType) codeBinOp(regTyp types.Type, op string, v1, v2 interface{}, er
 					"GOint64.mod("+v1string+","+v2string+","+isSignedStr+")", errorInfo)
 			case "*":
 				ret = l.intTypeCoersion(v1.(ssa.Value).Type().Underlying(),
-					"GOint64.mul("+v1string+","+v2string+")", errorInfo)
+					"GOint64.mul"+trap+"("+v1string+","+v2string+")", errorInfo)
 			case "|":
 				ret = l.intTypeCoersion(v1.(ssa.Value).Type().Underlying(),
 					"GOint64.or("+v1string+","+v2string+")", errorInfo)
@@ -246,7 +266,7 @@ func (l langType) codeBinOp(regTyp types.Type, op string, v1, v2 interface{}, er
 				}
 			case "-":
 				ret = l.intTypeCoersion(v1.(ssa.Value).Type().Underlying(),
-					"GOint64.sub("+v1string+","+v2string+")", errorInfo)
+					"GOint64.sub"+trap+"("+v1string+","+v2string+")", errorInfo)
 			case "^":
 				ret = l.intTypeCoersion(v1.(ssa.Value).Type().Underlying(),
 					"GOint64.xor("+v1string+","+v2string+")", errorInfo)
@@ -298,7 +318,7 @@ func (l langType) codeBinOp(regTyp types.Type, op string, v1, v2 interface{}, er
 				}
 			case ">>", "<<":
 				//v1string = wrapForceToUInt(v1string, v1.(ssa.Value).Type().Underlying().(*types.Basic).Kind())
-				v2string = wrapForceToUInt(v2string, v2.(ssa.Value).Type().Underlying().(*types.Basic).Kind())
+				v2string = shiftCount(v2, v2string)
 				switch v1.(ssa.Value).Type().Underlying().(*types.Basic).Kind() {
 				case types.Uint, types.Uint8, types.Uint16, types.Uint32, types.Uintptr: // unsigned bit shift
 					if op == ">>" {
@@ -356,6 +376,10 @@ func (l langType) codeBinOp(regTyp types.Type, op string, v1, v2 interface{}, er
 				}
 
 			case "*":
+				if sz := l.trapOverflowSize(v1.(ssa.Value).Type()); sz > 0 {
+					ret = fmt.Sprintf("Force.mulTrap(%s,%s,%d)", v1string, v2string, sz)
+					break
+				}
 				switch v1.(ssa.Value).Type().Underlying().(*types.Basic).Kind() {
 				case types.Int8:
 					ret = "Force.intMul(" + v1string + "," + v2string + ", 1)"
@@ -382,11 +406,28 @@ func (l langType) codeBinOp(regTyp types.Type, op string, v1, v2 interface{}, er
 				ret = "((" + v1string + ")" + op + "(" + v2string + "))"
 
 			case "+", "-":
+				if v1.(ssa.Value).Type().Underlying().(*types.Basic).Kind() == types.Uintptr {
+					// the uintptr may hold a Pointer, so no integer coersion of the result
+					fn := "Force.uintptrAdd("
+					if op == "-" {
+						fn = "Force.uintptrSub("
+					}
+					return fn + l.IndirectValue(v1, errorInfo) + "," + l.IndirectValue(v2, errorInfo) + ")"
+				}
+				if sz := l.trapOverflowSize(v1.(ssa.Value).Type()); sz > 0 {
+					fn := "Force.addTrap("
+					if op == "-" {
+						fn = "Force.subTrap("
+					}
+					ret = fmt.Sprintf("%s%s,%s,%d)", fn, v1string, v2string, sz)
+					break
+				}
 				ret = "(" + v1string + op + v2string + ")"
 
 			default:
 				panic("haxe unhandled binary operator: " + op)
 			}
+			// NOTE float32 results are rounded to float32 after every operation, as Go does
 			ret = l.intTypeCoersion(
 				regTyp.Underlying(),
 				ret, errorInfo)
@@ -396,6 +437,79 @@ func (l langType) codeBinOp(regTyp types.Type, op string, v1, v2 interface{}, er
 	}
 }
 
+// nativeInt64Op gives the haxe.Int64 operator expression for a 64-bit binary op, if the target has native 64-bit integers
+// and the operator gives the result Go defines, otherwise "" so that the GOint64 emulation is used.
+// Division needs the GOint64 checks, unsigned ordering has no operator, and shifts only match Go for constant counts below 64.
+func (l langType) nativeInt64Op(op string, v1, v2 interface{}, v1string, v2string string, trap bool) string {
+	if !l.hc.langEntry.NativeInt64 {
+		return ""
+	}
+	unsigned := (v1.(ssa.Value).Type().Underlying().(*types.Basic).Info() & types.IsUnsigned) != 0
+	x := "(" + v1string + ":haxe.Int64)"
+	y := "(" + v2string + ":haxe.Int64)"
+	switch op {
+	case "+", "-", "*":
+		if trap {
+			return ""
+		}
+	case "&", "|", "^", "==", "!=":
+	case "&^":
+		op = "&~"
+	case "<", ">", "<=", ">=":
+		if unsigned {
+			return ""
+		}
+	case "<<", ">>":
+		c, ok := v2.(*ssa.Const)
+		if !ok || c.Value == nil {
+			return ""
+		}
+		n, exact := constant.Uint64Val(constant.ToInt(c.Value))
+		if !exact || n > 63 {
+			return ""
+		}
+		y = fmt.Sprintf("%d", n)
+		if op == ">>" && unsigned {
+			op = ">>>"
+		}
+	default:
+		return ""
+	}
+	return "(" + x + op + y + ")"
+}
+
+// trapOverflowSize gives the size in bytes of a signed integer type, if arithmetic overflow of it should panic, otherwise 0
+func (l langType) trapOverflowSize(t types.Type) int64 {
+	if !l.hc.langEntry.TrapOverflow {
+		return 0
+	}
+	if b, ok := t.Underlying().(*types.Basic); ok {
+		switch b.Kind() {
+		case types.Int8, types.Int16, types.Int32, types.Int, types.Int64:
+			return haxeStdSizes.Sizeof(b)
+		}
+	}
+	return 0
+}
+
+// shiftCount gives the shift amount as an Int, amounts that do not fit become -1,
+// so that, like any other amount >= the width of the value shifted, the shift gives 0 or the sign fill
+func shiftCount(v2 interface{}, v string) string {
+	if c, ok := v2.(*ssa.Const); ok && c.Value != nil { // the amount is known at compile time
+		n, exact := constant.Uint64Val(constant.ToInt(c.Value))
+		if !exact || n > math.MaxInt32 {
+			return "-1"
+		}
+		return fmt.Sprintf("%d", n)
+	}
+	k := v2.(ssa.Value).Type().Underlying().(*types.Basic).Kind()
+	switch k {
+	case types.Int64, types.Uint64:
+		return "(GOint64.getHigh(" + v + ")!=0?-1:Force.toUint32(GOint64.getLow(" + v + ")))"
+	}
+	return wrapForceToUInt(v, k)
+}
+
 func (l langType) BinOp(register string, regTyp types.Type, op string, v1, v2 interface{}, errorInfo string) string {
 	return register + "=" + l.codeBinOp(regTyp, op, v1, v2, errorInfo) + ";"
 }
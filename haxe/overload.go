@@ -6,6 +6,7 @@ package haxe
 
 import (
 	"go/ast"
+	"strings"
 
 	"golang.org/x/tools/go/ssa"
 )
@@ -65,8 +66,22 @@ var fnToVarOverloadMap = map[string]string{
 //"math_NaN": "Math.NaN",
 }
 
+// FunctionOverloaded reports if calls to the Go function should be replaced by some other code.
+//
+// This includes every function in a package whose name starts with "_", which is the Go stub of a Haxe API.
+// In such a package the function name gives the Haxe target letter (X for all targets), the Haxe class path and function,
+// then a "_" and a digit that is discarded, so that each form of an overloaded Haxe function can have its own Go signature.
+// For example, both forms of Haxe's StringTools.hex(n:Int,?digits:Int) are declared in the Go stub as:
+//
+//	func XStringTools_hex_1(n int) string { return "" }
+//	func XStringTools_hex_2(n, digits int) string { return "" }
+//
+// The Go type checker then chooses the form, and each call becomes StringTools.hex(...) with the arguments given.
 func (l langType) FunctionOverloaded(pkg, fun string) bool {
 	//fmt.Printf("DEBUG fn ov :%s:%s:\n", pkg, fun)
+	if strings.HasPrefix(pkg, "_") { // the package is not in Go, but in the target language
+		return true
+	}
 	_, ok := fnOverloadMap[pkg+"_"+fun]
 	if ok {
 		return true
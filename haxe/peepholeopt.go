@@ -60,11 +60,11 @@ func (l langType) PeepholeOpt(opt, register string, code []ssa.Instruction, erro
 			ret += " PEEPHOLE OPTIMIZATION pointerChain\n"
 		} else {
 			ret += register + "="
-			if l.PogoComp().DebugFlag {
+			if l.checkNil() {
 				ret += "Pointer.check("
 			}
 			ret += basePointer
-			if l.PogoComp().DebugFlag {
+			if l.checkNil() {
 				ret += ")"
 			}
 			ret += ".addr(" + chainGang
@@ -124,6 +124,12 @@ func (l langType) PeepholeOpt(opt, register string, code []ssa.Instruction, erro
 				suffix, idx, ptrString)
 		}
 
+	case "elideBox":
+		ret += fmt.Sprintf("// %s=%s\n", code[0].(*ssa.MakeInterface).Name(), code[0].String())
+		ret += fmt.Sprintf("// %s=%s\n", code[1].(*ssa.TypeAssert).Name(), code[1].String())
+		ret += register + "=" + l.IndirectValue(code[0].(*ssa.MakeInterface).X, errorInfo) +
+			"; // PEEPHOLE OPTIMIZATION elideBox\n"
+
 	case "phiList":
 		//ret += "// PEEPHOLE OPTIMIZATION phiList\n"
 		//ret += l.PhiCode(true, 0, code, errorInfo)
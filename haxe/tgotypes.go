@@ -3,6 +3,8 @@ package haxe
 import (
 	"fmt"
 	"reflect"
+	"sort"
+	"strconv"
 	"unicode"
 	"unicode/utf8"
 
@@ -21,7 +23,7 @@ func escapedTypeString(s string) string {
 	buf := []byte(s)
 	r := ""
 	for _, ch := range buf {
-		r += fmt.Sprintf("\\x%x", ch)
+		r += fmt.Sprintf("\\x%02x", ch) // Haxe needs two hex digits, or a following hex character would be taken as part of the escape
 	}
 	return r
 }
@@ -180,6 +182,12 @@ func (l langType) typeBuild(i int, t types.Type) string {
 	if basic, isBasic := t.(*types.Basic); isBasic {
 		name = basic.Name()
 	}
+	if !l.PogoComp().TypeInfoNeeded(t) { // a minimal entry, keeping the id, string, kind and size, but nothing for reflect
+		rtype, _ := l.rtypeBuild(i, sizes, t, name)
+		ret += fmt.Sprintf("Go_haxegoruntime_fillRRtype.callFromRT(0,type%dptr,%s)", i, rtype)
+		ret += fmt.Sprintf(";}; return type%dptr; }\n", i)
+		return ret
+	}
 	rtype, kind := l.rtypeBuild(i, sizes, t, name)
 
 	switch t.(type) {
@@ -256,7 +264,7 @@ func (l langType) typeBuild(i int, t types.Type) string {
 			fret += "\n\t\t/*name:*/ \"" + name + "\",\n"
 			fret += "\t\t/*pkgPath:*/ \"" + path + "\",\n"
 			fret += fmt.Sprintf("\t\t/*typ:*/ type%d(),// %s\n", l.hc.pte.At(fldInfo.Type()), fldInfo.Type().String())
-			fret += "\t\t/*tag:*/ \"" + escapedTypeString(t.(*types.Struct).Tag(fld)) + "\", // " + t.(*types.Struct).Tag(fld) + "\n"
+			fret += "\t\t/*tag:*/ \"" + escapedTypeString(t.(*types.Struct).Tag(fld)) + "\", // " + strconv.Quote(t.(*types.Struct).Tag(fld)) + "\n" // a raw tag may contain a newline
 			fret += fmt.Sprintf("\t\t/*offset:*/ %d\n", offs[fld])
 
 			fret += "\t)"
@@ -356,6 +364,10 @@ func (l langType) rtypeBuild(i int, sizes types.Sizes, t types.Type, name string
 	}
 	ret += fmt.Sprintf("\t/*comprable:*/ %s,\n", alg) // TODO change this to be the actual function
 	ret += fmt.Sprintf("\t/*string:*/ \"%s\", // %s\n", escapedTypeString(t.String()), t.String())
+	if !l.PogoComp().TypeInfoNeeded(t) {
+		ret += "\t/*uncommonType:*/ null,\n\t/*ptrToThis:*/ null)"
+		return ret, kind
+	}
 	ret += fmt.Sprintf("\t/*uncommonType:*/ %s,\n", l.uncommonBuild(i, sizes, name, t))
 	ptt := "null"
 	for pti, pt := range l.hc.typesByID {
@@ -374,6 +386,18 @@ func (l langType) rtypeBuild(i int, sizes types.Sizes, t types.Type, name string
 	return ret, kind
 }
 
+// methodSorter orders method selections by name, then package path, as Go's reflect expects
+type methodSorter []*types.Selection
+
+func (a methodSorter) Len() int      { return len(a) }
+func (a methodSorter) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+func (a methodSorter) Less(i, j int) bool {
+	if a[i].Obj().Name() != a[j].Obj().Name() {
+		return a[i].Obj().Name() < a[j].Obj().Name()
+	}
+	return a[i].Obj().Id() < a[j].Obj().Id()
+}
+
 func (l langType) uncommonBuild(i int, sizes types.Sizes, name string, t types.Type) string {
 	pkgPath := ""
 	tt := t
@@ -405,57 +429,60 @@ func (l langType) uncommonBuild(i int, sizes types.Sizes, name string, t types.T
 		ret += "\t\t/*pkgPath:*/ \"" + pkgPath + "\",\n"
 		ret += "\t\t/*methods:*/ "
 		meths := "Go_haxegoruntime_newMMethodSSlice.callFromRT(0)"
-		//_, isIF := t.Underlying().(*types.Interface)
-		//if !isIF {
-		for m := 0; m < numMethods; m++ {
-			sel := methods.At(m)
-			ssaFn := l.PogoComp().RootProgram().MethodValue(sel)
-			if l.PogoComp().FnIsCalled(ssaFn) {
-				fn := "null"
-				fnToCall := "null"
-				var name, str, path string
-				fid, haveFn := l.hc.pte.At(sel.Obj().Type()).(int)
-				if haveFn {
-					fn = fmt.Sprintf("type%d()", fid)
-				}
-				name = sel.Obj().Name()
-				str = sel.String()
-				funcObj, ok := sel.Obj().(*types.Func)
-				if ok {
-					pn := "unknown"
-					if funcObj.Pkg() != nil {
-						pn = sel.Obj().Pkg().Name()
-						path = sel.Obj().Pkg().Path()
-					}
-					fnToCall = `Go_` + l.LangName(
-						pn+":"+sel.Recv().String(),
-						funcObj.Name())
+		// all methods are listed, in the same order as Go's reflect, so that NumMethod() and Method(i) work;
+		// methods that are never called, or are implemented by Haxe classes, have null function references
+		sels := make(methodSorter, 0, numMethods)
+		_, isIF := t.Underlying().(*types.Interface)
+		if !isIF { // interface methods are described by the interfaceType
+			for m := 0; m < numMethods; m++ {
+				sels = append(sels, methods.At(m))
+			}
+		}
+		sort.Sort(sels)
+		for _, sel := range sels {
+			fn := "null"
+			fnToCall := "null"
+			var name, str, path string
+			fid, haveFn := l.hc.pte.At(sel.Obj().Type()).(int)
+			if haveFn {
+				fn = fmt.Sprintf("type%d()", fid)
+			}
+			name = sel.Obj().Name()
+			str = sel.String()
+			funcObj, ok := sel.Obj().(*types.Func)
+			if ok {
+				pn := "unknown"
+				if funcObj.Pkg() != nil {
+					pn = sel.Obj().Pkg().Name()
+					path = sel.Obj().Pkg().Path()
 				}
+				fnToCall = `Go_` + l.LangName(
+					pn+":"+sel.Recv().String(),
+					funcObj.Name())
+			}
 
-				// now write out the method information
-				meths = "Go_haxegoruntime_addMMethod.callFromRT(0," + meths + ",\n"
-				meths += fmt.Sprintf("\n\t\t\t/*name:*/ \"%s\", // %s\n", name, str)
-				rune1, _ := utf8.DecodeRune([]byte(name))
-				if unicode.IsUpper(rune1) {
-					path = ""
-				}
+			// now write out the method information
+			meths = "Go_haxegoruntime_addMMethod.callFromRT(0," + meths + ",\n"
+			meths += fmt.Sprintf("\n\t\t\t/*name:*/ \"%s\", // %s\n", name, str)
+			rune1, _ := utf8.DecodeRune([]byte(name))
+			if unicode.IsUpper(rune1) {
+				path = ""
+			}
 
-				meths += fmt.Sprintf("\t\t\t/*pkgPath:*/ \"%s\",\n", path)
-				// TODO should the two lines below be different?
-				meths += fmt.Sprintf("\t\t\t/*mtyp:*/ %s,\n", fn)
-				meths += fmt.Sprintf("\t\t\t/*typ:*/ %s,\n", fn)
-				// add links to the functions ...
-
-				if l.hc.funcNamesUsed[fnToCall] {
-					fnToCall += ".call"
-				} else {
-					//println("DEBUG uncommonBuild function name not found: ", fnToCall)
-					fnToCall = "null /* " + fnToCall + " */ "
-				}
-				meths += "\t\t\t" + fnToCall + "," + fnToCall + ")"
+			meths += fmt.Sprintf("\t\t\t/*pkgPath:*/ \"%s\",\n", path)
+			// TODO should the two lines below be different?
+			meths += fmt.Sprintf("\t\t\t/*mtyp:*/ %s,\n", fn)
+			meths += fmt.Sprintf("\t\t\t/*typ:*/ %s,\n", fn)
+			// add links to the functions ...
+
+			if l.hc.funcNamesUsed[fnToCall] {
+				fnToCall += ".call"
+			} else {
+				//println("DEBUG uncommonBuild function name not found: ", fnToCall)
+				fnToCall = "null /* " + fnToCall + " */ "
 			}
+			meths += "\t\t\t" + fnToCall + "," + fnToCall + ")"
 		}
-		//}
 		ret += meths
 		return ret + "\t)"
 	}
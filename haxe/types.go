@@ -189,7 +189,7 @@ func (l langType) Convert(register, langType string, destType types.Type, v inte
 		// but some Go code uses uintptr as just another integer, so ensure it is unsigned
 		switch srcTyp {
 		case "GOint64":
-			vInt = "Force.toUint32(GOint64.toInt(" + vInt + "))"
+			vInt = "Force.toUint32(" + l.int64Low(vInt) + ")"
 		case "Float":
 			vInt = "Force.toUint32({var _f:Float=" + vInt + ";_f>=0?Math.floor(_f):Math.ceil(_f);})" // same as signed
 		case "Int":
@@ -230,7 +230,7 @@ func (l langType) Convert(register, langType string, destType types.Type, v inte
 			//return register + "=({var _ret:String;var _r:Slice=Go_haxegoruntime_RRune2RRaw.callFromRT(this._goroutine,GOint64.toInt(" + l.IndirectValue(v, errorInfo) + "));" +
 			//	"_ret=\"\";for(_i in 0..._r.len())" +
 			//	"_ret+=String.fromCharCode(_r.itemAddr(_i).load_int32(" + "));_ret;});"
-			return register + "=Force.stringFromRune(GOint64.toInt(" + l.IndirectValue(v, errorInfo) + "));"
+			return register + "=Force.stringFromRune(" + l.int64Low(l.IndirectValue(v, errorInfo)) + ");"
 		case "Dynamic":
 			return register + "=cast(" + l.IndirectValue(v, errorInfo) + ",String);"
 		default:
@@ -268,7 +268,7 @@ func (l langType) Convert(register, langType string, destType types.Type, v inte
 		case "Int":
 			vInt = l.IndirectValue(v, errorInfo) // to get the type coercion below
 		case "GOint64":
-			vInt = "GOint64.toInt(" + l.IndirectValue(v, errorInfo) + ")" // un/signed OK as just truncates
+			vInt = l.int64Low(l.IndirectValue(v, errorInfo)) // un/signed OK as just truncates
 		case "Float":
 			vInt = "{var _f:Float=" + l.IndirectValue(v, errorInfo) + ";_f>=0?Math.floor(_f):Math.ceil(_f);}"
 		case "Dynamic":
@@ -281,10 +281,8 @@ func (l langType) Convert(register, langType string, destType types.Type, v inte
 	case "GOint64":
 		switch srcTyp {
 		case "Int":
-			if v.(ssa.Value).Type().Underlying().(*types.Basic).Info()&types.IsUnsigned != 0 {
-				return register + "=GOint64.ofUInt(" + l.IndirectValue(v, errorInfo) + ");"
-			}
-			return register + "=GOint64.ofInt(" + l.IndirectValue(v, errorInfo) + ");"
+			unsigned := v.(ssa.Value).Type().Underlying().(*types.Basic).Info()&types.IsUnsigned != 0
+			return register + "=" + l.int64Of(l.IndirectValue(v, errorInfo), unsigned) + ";"
 		case "Float":
 			if destType.Underlying().(*types.Basic).Info()&types.IsUnsigned != 0 {
 				return register + "=GOint64.ofUFloat(" + l.IndirectValue(v, errorInfo) + ");"
@@ -334,6 +332,29 @@ func (l langType) Convert(register, langType string, destType types.Type, v inte
 	}
 }
 
+// int64Low gives the code for the low 32 bits of the 64-bit integer val, all that a conversion to a smaller integer keeps.
+// With -native64 the bits are read from the target's own haxe.Int64, rather than through the GOint64 emulation.
+func (l langType) int64Low(val string) string {
+	if l.hc.langEntry.NativeInt64 {
+		return "(" + val + ":haxe.Int64).low"
+	}
+	return "GOint64.toInt(" + val + ")"
+}
+
+// int64Of gives the code to widen the 32-bit integer val to 64 bits, natively with -native64.
+// NOTE conversions between int64 and Float always use GOint64, as haxe.Int64 has none with the results Go defines.
+func (l langType) int64Of(val string, unsigned bool) string {
+	switch {
+	case l.hc.langEntry.NativeInt64 && unsigned:
+		return "haxe.Int64.make(0," + val + ")"
+	case l.hc.langEntry.NativeInt64:
+		return "haxe.Int64.ofInt(" + val + ")"
+	case unsigned:
+		return "GOint64.ofUInt(" + val + ")"
+	}
+	return "GOint64.ofInt(" + val + ")"
+}
+
 func (l langType) MakeInterface(register string, regTyp types.Type, v interface{}, errorInfo string) string {
 	ret := `new Interface(` + l.PogoComp().LogTypeUse(v.(ssa.Value).Type() /*NOT underlying()*/) + `,` +
 		l.IndirectValue(v, errorInfo) + ")"
@@ -364,12 +385,21 @@ Type changes are permitted:
 */
 func (l langType) ChangeType(register string, regTyp interface{}, v interface{}, errorInfo string) string {
 	//fmt.Printf("DEBUG CHANGE TYPE: %v -- %v\n", regTyp, v)
+	hType := getHaxeClass(regTyp.(types.Type).String())
 	switch v.(ssa.Value).(type) {
 	case *ssa.Function:
-		return register + "=" +
-			"new Closure(Go_" + l.LangName(l.PogoComp().FuncPathName(v.(*ssa.Function))) + ".call,[]);"
+		closure := "new Closure(Go_" + l.LangName(l.PogoComp().FuncPathName(v.(*ssa.Function))) + ".call,[])"
+		if hType != "" { // a named function type implemented in Haxe, so the Closure must be cast, as below
+			return register + "=cast " + closure + ";"
+		}
+		return register + "=" + closure + ";"
 	default:
-		hType := getHaxeClass(regTyp.(types.Type).String())
+		if hType == "" && getHaxeClass(v.(ssa.Value).Type().String()) != "" {
+			if _, isFunc := v.(ssa.Value).Type().Underlying().(*types.Signature); isFunc {
+				// from a named function type implemented in Haxe back to a Go one, the inverse of the cast below
+				return register + "=cast " + l.IndirectValue(v, errorInfo) + ";"
+			}
+		}
 		if hType != "" {
 			switch v.(ssa.Value).Type().Underlying().(type) {
 			case *types.Interface:
@@ -390,6 +420,7 @@ func (l langType) ChangeType(register string, regTyp interface{}, v interface{},
 			}
 		}
 	}
+	// NOTE a channel narrowed to send- or receive-only also shares the Channel, so that == still works
 	return register + `=` + l.IndirectValue(v, errorInfo) + ";" // usually, this is a no-op as far as Haxe is concerned
 
 }
@@ -482,6 +513,13 @@ func (l langType) buildTBI() {
 		v := l.hc.pte.At(l.hc.pteKeys[k]).(int)
 		l.hc.typesByID[v] = l.hc.pteKeys[k]
 	}
+	// type strings are not unique, so emit everything in type id order to give the same output every time
+	l.hc.pteKeys = l.hc.pteKeys[:0]
+	for _, t := range l.hc.typesByID {
+		if t != nil {
+			l.hc.pteKeys = append(l.hc.pteKeys, t)
+		}
+	}
 }
 
 func (l langType) EmitTypeInfo() string {
@@ -512,28 +550,49 @@ func (l langType) EmitTypeInfo() string {
 	ret += "\t#if (js || php || node) if(id==null)return \"(haxeTypeID=null)\"; #end\n"
 	ret += "\t" + `return Go_haxegoruntime_getTTypeSString.callFromRT(0,id);` + "\n}\n"
 	ret += "public static function typeString(i:Interface):String {\nreturn getName(i.typ);\n}\n"
-	/*
-		ret += "static var typIDs:Map<String,Int> = ["
-		deDup := make(map[string]bool)
-		for k := range pteKeys {
-			v := pte.At(pteKeys[k])
-			nam := haxeStringConst("`"+preprocessTypeName(pteKeys[k].String())+"`", "CompilerInternal:haxe.EmitTypeInfo()")
-			if len(nam) != 0 {
-				if deDup[nam] { // have one already!!
-					nam = fmt.Sprintf("%s (duplicate type name! this id=%d)\"", nam[:len(nam)-1], v)
-				} else {
-					deDup[nam] = true
-				}
-				ret += ` ` + nam + ` => ` + fmt.Sprintf("%d", v) + `,` + "\n"
+	// the type name to id map is keyed by the full type string, so distinct types from different packages can't collide,
+	// names qualified only by the package name are kept in a secondary map, but only where they are unambiguous
+	typIDs := ""
+	shortIDs := make(map[string]int)
+	fullNames := make(map[string]int) // struct types with unexported fields can share a full type string
+	for k := range l.hc.pteKeys {
+		fullNames[l.hc.pteKeys[k].String()]++
+	}
+	for k := range l.hc.pteKeys {
+		v := l.hc.pte.At(l.hc.pteKeys[k]).(int)
+		full := l.hc.pteKeys[k].String()
+		if fullNames[full] == 1 { // duplicates are left for the runtime to resolve
+			typIDs += " " + l.haxeStringConst(strconv.Quote(full), "CompilerInternal:haxe.EmitTypeInfo()") +
+				fmt.Sprintf(" => %d,\n", v)
+		}
+		short := types.TypeString(l.hc.pteKeys[k], func(p *types.Package) string { return p.Name() })
+		if short != full && fullNames[short] == 0 {
+			if _, dup := shortIDs[short]; dup {
+				shortIDs[short] = -1 // ambiguous, so must not be used
+			} else {
+				shortIDs[short] = v
 			}
 		}
-		ret += "];\n"
-	*/
+	}
+	shortNames := make([]string, 0, len(shortIDs))
+	for short, v := range shortIDs {
+		if v != -1 {
+			shortNames = append(shortNames, short)
+		}
+	}
+	sort.Strings(shortNames) // make sure the map is always written in the same order
+	shortTypIDs := ""
+	for _, short := range shortNames {
+		shortTypIDs += " " + l.haxeStringConst(strconv.Quote(short), "CompilerInternal:haxe.EmitTypeInfo()") +
+			fmt.Sprintf(" => %d,\n", shortIDs[short])
+	}
+	ret += "static var typIDs:Map<String,Int> = " + haxeMapLiteral(typIDs) + ";\n"
+	ret += "static var shortTypIDs:Map<String,Int> = " + haxeMapLiteral(shortTypIDs) + ";\n"
+
 	ret += "public static function getId(name:String):Int {\n"
-	ret += "\tvar t:Int;\n"
-	//ret += "\ttry { t=typIDs[name];\n"
-	//ret += "\t} catch(x:Dynamic) { Scheduler.panicFromHaxe(\"TraceInfo.getId() not found:\"+name+x); t=-1; } ;\n"
-	ret += "\t" + `t = Go_haxegoruntime_getTTypeIIDD.callFromRT(0,name);` + "\n"
+	ret += "\tvar t:Null<Int>=typIDs.get(name);\n"
+	ret += "\tif(t==null) t=shortTypIDs.get(name);\n"
+	ret += "\tif(t==null) " + `t = Go_haxegoruntime_getTTypeIIDD.callFromRT(0,name);` + "\n" // e.g. byte or rune
 	ret += "\treturn t;\n}\n"
 
 	//function to answer the question is the type a concrete value?
@@ -617,6 +676,14 @@ func (l langType) EmitTypeInfo() string {
 	return ""
 }
 
+// haxeMapLiteral returns the entries as a Haxe Map literal, an empty literal would be an Array
+func haxeMapLiteral(entries string) string {
+	if entries == "" {
+		return "new Map<String,Int>()"
+	}
+	return "[\n" + entries + "]"
+}
+
 func fixKeyWds(w string) string {
 	switch w {
 	case "new":
@@ -664,6 +731,9 @@ func loadStoreSuffix(T types.Type, hasParameters bool) string {
 		}
 		return ret
 	}
+	if _, ok := T.Underlying().(*types.Pointer); ok {
+		return "_pointer("
+	}
 	return "(" // no suffix, so some dynamic type
 }
 
@@ -673,6 +743,16 @@ func (l langType) TypeStart(nt *types.Named, err string) string {
 	typName := "GoType" + l.LangName("", nt.String())
 	hxTyp := l.LangType(nt.Obj().Type(), false, nt.String())
 	ret := ""
+	if consts, isEnum := l.PogoComp().EnumConsts(nt); isEnum { // listed in tardisgoEnumList
+		ret += "@:enum abstract " + typName + "(" + hxTyp + ") from " + hxTyp + " to " + hxTyp + " {\n"
+		for _, c := range consts {
+			posStr := l.PogoComp().CodePosition(c.Pos())
+			_, rhs := l.Const(*c.Value, posStr)
+			ret += fmt.Sprintf("var %s = %s;%s\n", c.Name(), rhs, l.Comment(posStr))
+		}
+		l.PogoComp().WriteAsClass(typName, ret+"}\n")
+		return ""
+	}
 	switch hxTyp {
 	case "Object":
 		ret += "class " + typName
@@ -55,10 +55,12 @@ func Compile(mainPkg *ssa.Package, debug, trace bool, langName, testFSname strin
 	comp.emitTypeInfo()
 	comp.emitFileEnd()
 	if comp.hadErrors && comp.stopOnError {
+		comp.removeStreamedFiles()
 		err := fmt.Errorf("no output files generated")
 		comp.LogError("", "pogo", err)
 		return nil, err
 	}
+	comp.emitManifest()
 	comp.writeFiles()
 	return comp, nil
 }
@@ -75,6 +77,7 @@ func (comp *Compilation) emitGoClass(mainPkg *ssa.Package) {
 // special constant name used in TARDIS Go to put text in the header of files
 const pogoHeader = "tardisgoHeader"
 const pogoLibList = "tardisgoLibList"
+const pogoEnumList = "tardisgoEnumList"
 
 func (comp *Compilation) loadSpecialConsts() {
 	hxPkg := ""
@@ -131,6 +134,19 @@ func (comp *Compilation) loadSpecialConsts() {
 						comp.LogError(comp.CodePosition(lit.Pos()), "pogo",
 							fmt.Errorf("special targetPackage constant not a string"))
 					}
+				case pogoEnumList:
+					lit := mem.(*ssa.NamedConst).Value
+					switch lit.Value.Kind() {
+					case constant.String:
+						el, err := strconv.Unquote(lit.Value.String())
+						if err != nil {
+							comp.LogError(comp.CodePosition(lit.Pos())+"Special "+pogoEnumList+" constant ", "pogo", err)
+						}
+						comp.addEnumTypes(pkg, strings.Split(el, ","), comp.CodePosition(lit.Pos()))
+					default:
+						comp.LogError(comp.CodePosition(lit.Pos()), "pogo",
+							fmt.Errorf("special %s constant not a string", pogoEnumList))
+					}
 				}
 			}
 		}
@@ -1,6 +1,8 @@
 package pogo
 
 import (
+	"go/types"
+
 	"golang.org/x/tools/go/ssa"
 	"golang.org/x/tools/go/types/typeutil"
 )
@@ -20,6 +22,7 @@ type Compilation struct {
 	LatestValidPosHash PosHash             // LatestValidPosHash holds the latest valid PosHash value seen, for use when an invalid one requires a "near" reference.
 
 	fnMap, grMap map[*ssa.Function]bool // which functions are used and if the functions use goroutines/channels
+	dynamicFns   map[*ssa.Function]bool // which used functions are in run-time method tables, so may be called dynamically
 
 	inlineMap map[string]string
 	keysSeen  map[string]int
@@ -29,6 +32,16 @@ type Compilation struct {
 	TypesEncountered         typeutil.Map // TypesEncountered keeps track of the types we encounter using the excellent go.tools/go/types/typesmap package.
 	NextTypeID               int          // NextTypeID is used to give each type we come across its own ID - entry zero is invalid
 	catchReferencedTypesSeen map[string]bool
+	typeInfoNeeded           map[int]bool                       // the type ids that need full type information, nil if all of them do
+	manifest                 map[string]*funcManifest           // keyed by the FuncPathName identifiers, only used if LanguageEntry.Manifest is set
+	enumTypes                map[*types.Named][]*ssa.NamedConst // the named integer types listed in tardisgoEnumList, with their exported constants
+
+	// parallel emission, see workers.go
+	unitOf       *Compilation // if not nil, this is a unit emitting a run of that Compilation's functions on a worker goroutine
+	marks        *typeMarks   // the marks that stand in for type IDs while units are emitted
+	marksUsed    []int        // the type marks a unit has used, in the order of their first use
+	markSeen     map[int]bool // the type marks a unit has used
+	heldMessages []string     // the messages a unit has held back, to be given when it is joined
 
 	// flags
 	DebugFlag              bool // DebugFlag is used to signal if we are emitting debug information
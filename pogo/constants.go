@@ -8,8 +8,10 @@ import (
 	"fmt"
 	"go/constant"
 	"go/token"
+	"go/types"
 	"sort"
 	"strconv"
+	"strings"
 
 	"golang.org/x/tools/go/ssa"
 )
@@ -30,6 +32,12 @@ func (comp *Compilation) emitNamedConstants() {
 				switch lit.Value.Kind() {                            // non language specific validation
 				case constant.Bool, constant.String, constant.Float, constant.Int, constant.Complex: //OK
 					isPublic := mem.Object().Exported()
+					if nt, isEnum := lit.Type().(*types.Named); isEnum && isPublic {
+						if _, isEnum = comp.enumTypes[nt]; isEnum { // emitted as a group by emitEnumTypes()
+							comp.enumTypes[nt] = append(comp.enumTypes[nt], mem.(*ssa.NamedConst))
+							break
+						}
+					}
 					if isPublic { // constants will be inserted inline, these declarations of public constants are for exteral use in target language
 						l := comp.TargetLang
 						fmt.Fprintln(&LanguageList[l].buffer, LanguageList[l].NamedConst(pName, mName, *lit, posStr))
@@ -43,6 +51,57 @@ func (comp *Compilation) emitNamedConstants() {
 	}
 }
 
+// addEnumTypes records the named integer types, declared in pkg, whose exported constants should be emitted as a target language enum.
+func (comp *Compilation) addEnumTypes(pkg *ssa.Package, names []string, posStr string) {
+	if comp.enumTypes == nil {
+		comp.enumTypes = make(map[*types.Named][]*ssa.NamedConst)
+	}
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		tn, ok := pkg.Pkg.Scope().Lookup(name).(*types.TypeName)
+		if !ok {
+			comp.LogError(posStr, "pogo", fmt.Errorf("%s lists %s, which is not a type in package %s",
+				pogoEnumList, name, pkg.Pkg.Path()))
+			continue
+		}
+		nt, ok := tn.Type().(*types.Named)
+		if ok {
+			b, isBasic := nt.Underlying().(*types.Basic)
+			ok = isBasic && b.Info()&types.IsInteger != 0 && b.Kind() != types.Int64 && b.Kind() != types.Uint64
+		}
+		if !ok {
+			comp.LogError(posStr, "pogo", fmt.Errorf("%s lists %s, which is not a named integer type of 32 bits or less",
+				pogoEnumList, name))
+			continue
+		}
+		comp.enumTypes[nt] = nil
+	}
+}
+
+// EnumConsts returns the exported constants of a type listed in tardisgoEnumList, in value order,
+// with ok false if the type is not listed.
+func (comp *Compilation) EnumConsts(nt *types.Named) (consts []*ssa.NamedConst, ok bool) {
+	consts, ok = comp.enumTypes[nt]
+	return
+}
+
+// emit the enum types, after their constants have been collected by emitNamedConstants()
+func (comp *Compilation) emitEnumTypes() {
+	enums := TypeSorter{}
+	for nt, consts := range comp.enumTypes {
+		sort.Stable(constSorter(consts))
+		enums = append(enums, nt)
+	}
+	sort.Sort(enums)
+	l := comp.TargetLang
+	for _, nt := range enums {
+		fmt.Fprintln(&LanguageList[l].buffer, LanguageList[l].TypeStart(nt.(*types.Named), nt.String()))
+	}
+}
+
 // FloatVal is a utility function returns a string constant value from a constant.Value.
 func (comp *Compilation) FloatVal(eVal constant.Value, bits int, posStr string) string {
 	fVal, isExact := constant.Float64Val(eVal)
@@ -26,7 +26,15 @@ func (comp *Compilation) initErrors() {
 // Utility message handler for errors
 func (comp *Compilation) logMessage(level, loc, lang string, err error) {
 	msg := fmt.Sprintf("%s : %s (%s) %v \n", level, loc, lang, err)
-	// don't emit duplicate messages
+	if comp.unitOf != nil { // given when the unit is joined, so in the same order however many workers there are
+		comp.heldMessages = append(comp.heldMessages, msg)
+		return
+	}
+	comp.giveMessage(msg)
+}
+
+// giveMessage writes a message to stderr, unless it has been given already.
+func (comp *Compilation) giveMessage(msg string) {
 	_, hadIt := comp.messagesGiven[msg]
 	if !hadIt {
 		fmt.Fprintf(os.Stderr, "%s", msg)
@@ -26,6 +26,26 @@ func (comp *Compilation) FnIsCalled(fn *ssa.Function) bool {
 	return comp.fnMap[fn]
 }
 
+// FnIsDynamic tells if the function is a method of a type known at run-time,
+// so it may be called via interface method dispatch or reflect rather than by name.
+func (comp *Compilation) FnIsDynamic(fn *ssa.Function) bool {
+	return comp.dynamicFns[fn]
+}
+
+// markDynamicFunctions records the used methods that appear in the run-time method tables.
+func (comp *Compilation) markDynamicFunctions() {
+	comp.dynamicFns = make(map[*ssa.Function]bool)
+	for _, T := range comp.rootProgram.RuntimeTypes() {
+		mset := comp.rootProgram.MethodSets.MethodSet(T)
+		for i := 0; i < mset.Len(); i++ {
+			fn := comp.rootProgram.MethodValue(mset.At(i))
+			if fn != nil && comp.fnMap[fn] {
+				comp.dynamicFns[fn] = true
+			}
+		}
+	}
+}
+
 // For every function, maybe emit the code...
 func (comp *Compilation) emitFunctions() {
 	dceList := []*ssa.Package{
@@ -46,6 +66,10 @@ func (comp *Compilation) emitFunctions() {
 		}
 	}
 	comp.fnMap, comp.grMap = tgossa.VisitedFunctions(comp.rootProgram, dceList, comp.IsOverloaded)
+	if LanguageList[comp.TargetLang].MaxNativeDepth > 0 { // recursive functions must be able to yield to the scheduler
+		tgossa.RecursionUsesGR(comp.fnMap, comp.grMap, comp.IsOverloaded)
+	}
+	comp.markDynamicFunctions()
 
 	/* NOTE non-working code below attempts to improve Dead Code Elimination,
 	//	but is unreliable so far, in part because the target lang runtime may use "unsafe" pointers
@@ -191,14 +215,22 @@ func (comp *Compilation) emitFunctions() {
 		dupCheck[p+"."+n] = f
 	}
 
+	fns := []*ssa.Function{}
 	for _, f := range comp.fnMapSorted() {
 		if !comp.IsOverloaded(f) {
 			if err := tgossa.CheckNames(f); err != nil {
 				panic(err)
 			}
-			comp.emitFunc(f)
+			fns = append(fns, f)
 		}
 	}
+	if LanguageList[comp.TargetLang].Workers > 1 {
+		comp.emitUnits(fns)
+		return
+	}
+	for _, f := range fns {
+		comp.emitFunc(f, comp.planFunc(f))
+	}
 }
 
 // IsOverloaded reports if a function reference should be replaced
@@ -235,11 +267,7 @@ func (comp *Compilation) IsOverloaded(f *ssa.Function) bool {
 	ts := tss[len(tss)-1]         // take the last part of the path
 	pn = ts                       // TODO this is incorrect, but not currently a problem as there is no function overloading
 	//println("DEBUG package name: " + pn)
-	if LanguageList[comp.TargetLang].FunctionOverloaded(pn, f.Name()) ||
-		strings.HasPrefix(pn, "_") { // the package is not in the target language, signaled by a leading underscore and
-		return true
-	}
-	return false
+	return LanguageList[comp.TargetLang].FunctionOverloaded(pn, f.Name())
 }
 
 //------------------------------------------------------------------------------------------------------------
@@ -255,8 +283,18 @@ type subFnInstrs struct {
 	end   int
 }
 
-// Emit a particular function.
-func (comp *Compilation) emitFunc(fn *ssa.Function) {
+// funcPlan holds the language-independent analysis of a function, made before its code is emitted.
+type funcPlan struct {
+	blks          []*ssa.BasicBlock    // the blocks, in dominator order
+	trackPhi      bool                 // are there Phi instructions to track?
+	mustSplitCode bool                 // is the function too large to emit as a single target function?
+	subFnList     []subFnInstrs        // where the sub-functions are
+	reconstruct   []tgossa.BlockFormat // how the blocks can be structured in the target language
+}
+
+// planFunc analyses a function ready for emitFunc, returning nil if it has no body.
+// It may be called for several functions at once, so must only read shared state, and must not call the target language.
+func (comp *Compilation) planFunc(fn *ssa.Function) *funcPlan {
 
 	/* TODO research if the ssautil.Switches() function can be incorporated to provide any run-time improvement to the code
 	// it would give a big adavantage, but only to a very small number of functions - so definately TODO
@@ -267,203 +305,216 @@ func (comp *Compilation) emitFunc(fn *ssa.Function) {
 	}
 	*/
 
-	var subFnList []subFnInstrs        // where the sub-functions are
-	canOptMap := make(map[string]bool) // TODO review use of this mechanism
+	var subFnList []subFnInstrs // where the sub-functions are
 
-	//println("DEBUG processing function: ", fn.Name())
-	comp.MakePosHash(fn.Pos()) // mark that we have entered a function
 	trackPhi := true
 	switch len(fn.Blocks) {
 	case 0: // NoOp - only output a function if it has a body... so ignore pure definitions (target language may generate an error, if truely undef)
 		//fmt.Printf("DEBUG function has no body, ignored: %v %v \n", fn.Name(), fn.String())
+		return nil
 	case 1: // Only one block, so no Phi tracking required
 		trackPhi = false
-		fallthrough
-	default:
-		if trackPhi {
-			// check that there actually are Phi instructions to track
-			trackPhi = false
-		phiSearch:
-			for b := range fn.Blocks {
-				for i := range fn.Blocks[b].Instrs {
-					_, trackPhi = fn.Blocks[b].Instrs[i].(*ssa.Phi)
-					if trackPhi {
-						break phiSearch
-					}
+	}
+	if trackPhi {
+		// check that there actually are Phi instructions to track
+		trackPhi = false
+	phiSearch:
+		for b := range fn.Blocks {
+			for i := range fn.Blocks[b].Instrs {
+				_, trackPhi = fn.Blocks[b].Instrs[i].(*ssa.Phi)
+				if trackPhi {
+					break phiSearch
 				}
 			}
 		}
-		instrCount := 0
-		for b := range fn.Blocks {
-			instrCount += len(fn.Blocks[b].Instrs)
-		}
-		mustSplitCode := false
-		if instrCount > LanguageList[comp.TargetLang].InstructionLimit {
-			//println("DEBUG mustSplitCode => large function length:", instrCount, " in ", fn.Name())
-			mustSplitCode = true
-		}
-		blks := fn.DomPreorder() // was fn.Blocks
-		for b := range blks {    // go though the blocks looking for sub-functions
-			instrsEmitted := 0
-			inSubFn := false
-			for i := range blks[b].Instrs {
-				canPutInSubFn := true
-				in := blks[b].Instrs[i]
-				switch in.(type) {
-				case *ssa.Phi: // phi uses self-referential temp vars that must be pre-initialised
-					canPutInSubFn = false
-				case *ssa.Return:
+	}
+	instrCount := 0
+	for b := range fn.Blocks {
+		instrCount += len(fn.Blocks[b].Instrs)
+	}
+	mustSplitCode := false
+	if instrCount > LanguageList[comp.TargetLang].InstructionLimit {
+		//println("DEBUG mustSplitCode => large function length:", instrCount, " in ", fn.Name())
+		mustSplitCode = true
+	}
+	blks := fn.DomPreorder() // was fn.Blocks
+	for b := range blks {    // go though the blocks looking for sub-functions
+		instrsEmitted := 0
+		inSubFn := false
+		for i := range blks[b].Instrs {
+			canPutInSubFn := true
+			in := blks[b].Instrs[i]
+			switch in.(type) {
+			case *ssa.Phi: // phi uses self-referential temp vars that must be pre-initialised
+				canPutInSubFn = false
+			case *ssa.Return:
+				canPutInSubFn = false
+			case *ssa.Call:
+				switch in.(*ssa.Call).Call.Value.(type) {
+				case *ssa.Builtin:
+					//NoOp
+				default:
 					canPutInSubFn = false
-				case *ssa.Call:
-					switch in.(*ssa.Call).Call.Value.(type) {
-					case *ssa.Builtin:
-						//NoOp
-					default:
-						canPutInSubFn = false
-					}
-				case *ssa.Select, *ssa.Send, *ssa.Defer, *ssa.RunDefers, *ssa.Panic:
+				}
+			case *ssa.Select, *ssa.Send, *ssa.Defer, *ssa.RunDefers, *ssa.Panic:
+				canPutInSubFn = false
+			case *ssa.UnOp:
+				if in.(*ssa.UnOp).Op == token.ARROW {
 					canPutInSubFn = false
-				case *ssa.UnOp:
-					if in.(*ssa.UnOp).Op == token.ARROW {
-						canPutInSubFn = false
-					}
 				}
-				if canPutInSubFn {
-					if inSubFn {
-						if instrsEmitted > LanguageList[comp.TargetLang].SubFnInstructionLimit {
-							subFnList[len(subFnList)-1].end = i
-							subFnList = append(subFnList, subFnInstrs{b, i, 0})
-							instrsEmitted = 0
-						}
-					} else {
+			}
+			if canPutInSubFn {
+				if inSubFn {
+					if instrsEmitted > LanguageList[comp.TargetLang].SubFnInstructionLimit {
+						subFnList[len(subFnList)-1].end = i
 						subFnList = append(subFnList, subFnInstrs{b, i, 0})
-						inSubFn = true
+						instrsEmitted = 0
 					}
 				} else {
-					if inSubFn {
-						subFnList[len(subFnList)-1].end = i
-						inSubFn = false
-					}
+					subFnList = append(subFnList, subFnInstrs{b, i, 0})
+					inSubFn = true
+				}
+			} else {
+				if inSubFn {
+					subFnList[len(subFnList)-1].end = i
+					inSubFn = false
 				}
-				instrsEmitted++
-			}
-			if inSubFn {
-				subFnList[len(subFnList)-1].end = len(blks[b].Instrs)
 			}
+			instrsEmitted++
 		}
-		for sf := range subFnList { // go though the sub-functions looking for optimisable temp vars
-			var instrMap = make(map[ssa.Instruction]bool)
-			for ii := subFnList[sf].start; ii < subFnList[sf].end; ii++ {
-				instrMap[blks[subFnList[sf].block].Instrs[ii]] = true
-			}
+		if inSubFn {
+			subFnList[len(subFnList)-1].end = len(blks[b].Instrs)
+		}
+	}
 
-			for i := subFnList[sf].start; i < subFnList[sf].end; i++ {
-				instrVal, hasVal := blks[subFnList[sf].block].Instrs[i].(ssa.Value)
-				if hasVal {
-					refs := *blks[subFnList[sf].block].Instrs[i].(ssa.Value).Referrers()
-					switch len(refs) {
-					case 0: // no other instruction uses the result of this one
-					default: //multiple usage of the register
-						canOpt := true
-						for r := range refs {
-							user := refs[r]
-							if user.Block() != blks[subFnList[sf].block] {
-								canOpt = false
-								break
-							}
-							_, inRange := instrMap[user]
-							if !inRange {
-								canOpt = false
-								break
-							}
+	reconstruct := tgossa.Reconstruct(blks, comp.grMap[fn] || mustSplitCode)
+	if reconstruct != nil {
+		//fmt.Printf("DEBUG reconstruct %s %#v\n",fn.String(),reconstruct)
+	}
+	return &funcPlan{blks, trackPhi, mustSplitCode, subFnList, reconstruct}
+}
+
+// Emit a particular function, following the plan made for it by planFunc.
+func (comp *Compilation) emitFunc(fn *ssa.Function, plan *funcPlan) {
+	//println("DEBUG processing function: ", fn.Name())
+	comp.LatestValidPosHash = NoPosHash // nothing is carried over from the function before, which may have been emitted by another worker
+	comp.previousErrorInfo = ""
+	comp.MakePosHash(fn.Pos()) // mark that we have entered a function
+	if plan == nil {           // only output a function if it has a body
+		return
+	}
+	blks, trackPhi, mustSplitCode := plan.blks, plan.trackPhi, plan.mustSplitCode
+	subFnList, reconstruct := plan.subFnList, plan.reconstruct
+
+	canOptMap := make(map[string]bool) // TODO review use of this mechanism
+
+	for sf := range subFnList { // go though the sub-functions looking for optimisable temp vars
+		var instrMap = make(map[ssa.Instruction]bool)
+		for ii := subFnList[sf].start; ii < subFnList[sf].end; ii++ {
+			instrMap[blks[subFnList[sf].block].Instrs[ii]] = true
+		}
+
+		for i := subFnList[sf].start; i < subFnList[sf].end; i++ {
+			instrVal, hasVal := blks[subFnList[sf].block].Instrs[i].(ssa.Value)
+			if hasVal {
+				refs := *blks[subFnList[sf].block].Instrs[i].(ssa.Value).Referrers()
+				switch len(refs) {
+				case 0: // no other instruction uses the result of this one
+				default: //multiple usage of the register
+					canOpt := true
+					for r := range refs {
+						user := refs[r]
+						if user.Block() != blks[subFnList[sf].block] {
+							canOpt = false
+							break
 						}
-						if canOpt &&
-							!LanguageList[comp.TargetLang].CanInline(blks[subFnList[sf].block].Instrs[i]) {
-							canOptMap[instrVal.Name()] = true
+						_, inRange := instrMap[user]
+						if !inRange {
+							canOpt = false
+							break
 						}
 					}
+					if canOpt &&
+						!LanguageList[comp.TargetLang].CanInline(blks[subFnList[sf].block].Instrs[i]) {
+						canOptMap[instrVal.Name()] = true
+					}
 				}
 			}
 		}
+	}
 
-		reconstruct := tgossa.Reconstruct(blks, comp.grMap[fn] || mustSplitCode)
-		if reconstruct != nil {
-			//fmt.Printf("DEBUG reconstruct %s %#v\n",fn.String(),reconstruct)
-		}
-
-		comp.emitFuncStart(fn, blks, trackPhi, canOptMap, mustSplitCode, reconstruct)
-		thisSubFn := 0
-		for b := range blks {
-			emitPhi := trackPhi
-			comp.emitBlockStart(blks, b, emitPhi)
-			inSubFn := false
-			for i := 0; i < len(blks[b].Instrs); i++ {
-				if thisSubFn >= 0 && thisSubFn < len(subFnList) { // not at the end of the list
-					if b == subFnList[thisSubFn].block {
-						if i >= subFnList[thisSubFn].end && inSubFn {
-							inSubFn = false
-							thisSubFn++
-							if thisSubFn >= len(subFnList) {
-								thisSubFn = -1 // we have come to the end of the list
-							}
+	comp.emitFuncStart(fn, blks, trackPhi, canOptMap, mustSplitCode, reconstruct)
+	thisSubFn := 0
+	for b := range blks {
+		emitPhi := trackPhi
+		comp.emitBlockStart(blks, b, emitPhi)
+		inSubFn := false
+		for i := 0; i < len(blks[b].Instrs); i++ {
+			if thisSubFn >= 0 && thisSubFn < len(subFnList) { // not at the end of the list
+				if b == subFnList[thisSubFn].block {
+					if i >= subFnList[thisSubFn].end && inSubFn {
+						inSubFn = false
+						thisSubFn++
+						if thisSubFn >= len(subFnList) {
+							thisSubFn = -1 // we have come to the end of the list
 						}
 					}
 				}
-				if thisSubFn >= 0 && thisSubFn < len(subFnList) { // not at the end of the list
-					if b == subFnList[thisSubFn].block {
-						if i == subFnList[thisSubFn].start {
-							inSubFn = true
-							l := comp.TargetLang
-							if mustSplitCode {
-								fmt.Fprintln(&LanguageList[l].buffer, LanguageList[l].SubFnCall(thisSubFn))
-							} else {
-								comp.emitSubFn(fn, blks, subFnList, thisSubFn, mustSplitCode, canOptMap)
-							}
+			}
+			if thisSubFn >= 0 && thisSubFn < len(subFnList) { // not at the end of the list
+				if b == subFnList[thisSubFn].block {
+					if i == subFnList[thisSubFn].start {
+						inSubFn = true
+						l := comp.TargetLang
+						if mustSplitCode {
+							fmt.Fprintln(&LanguageList[l].buffer, LanguageList[l].SubFnCall(thisSubFn))
+						} else {
+							comp.emitSubFn(fn, blks, subFnList, thisSubFn, mustSplitCode, canOptMap)
 						}
 					}
 				}
-				if !inSubFn {
-					// optimize phi case statements
-					phiList := 0
-				phiLoop:
-					switch blks[b].Instrs[i+phiList].(type) {
-					case *ssa.Phi:
-						if len(*blks[b].Instrs[i+phiList].(*ssa.Phi).Referrers()) > 0 {
-							phiList++
-							if (i + phiList) < len(blks[b].Instrs) {
-								goto phiLoop
-							}
+			}
+			if !inSubFn {
+				// optimize phi case statements
+				phiList := 0
+			phiLoop:
+				switch blks[b].Instrs[i+phiList].(type) {
+				case *ssa.Phi:
+					if len(*blks[b].Instrs[i+phiList].(*ssa.Phi).Referrers()) > 0 {
+						phiList++
+						if (i + phiList) < len(blks[b].Instrs) {
+							goto phiLoop
 						}
 					}
-					if phiList > 0 {
-						comp.peephole(blks[b].Instrs[i : i+phiList])
-						i += phiList - 1
-					} else {
-						emitPhi = comp.emitInstruction(blks[b].Instrs[i],
-							blks[b].Instrs[i].Operands(make([]*ssa.Value, 0)))
-					}
+				}
+				if phiList > 0 {
+					comp.peephole(blks[b].Instrs[i : i+phiList])
+					i += phiList - 1
+				} else {
+					emitPhi = comp.emitInstruction(blks[b].Instrs[i],
+						blks[b].Instrs[i].Operands(make([]*ssa.Value, 0)))
 				}
 			}
-			if thisSubFn >= 0 && thisSubFn < len(subFnList) { // not at the end of the list
-				if b == subFnList[thisSubFn].block {
-					if inSubFn {
-						thisSubFn++
-						if thisSubFn >= len(subFnList) {
-							thisSubFn = -1 // we have come to the end of the list
-						}
+		}
+		if thisSubFn >= 0 && thisSubFn < len(subFnList) { // not at the end of the list
+			if b == subFnList[thisSubFn].block {
+				if inSubFn {
+					thisSubFn++
+					if thisSubFn >= len(subFnList) {
+						thisSubFn = -1 // we have come to the end of the list
 					}
 				}
 			}
-			comp.emitBlockEnd(blks, b, emitPhi && trackPhi)
 		}
-		comp.emitRunEnd(fn)
-		if mustSplitCode {
-			for sf := range subFnList {
-				comp.emitSubFn(fn, blks, subFnList, sf, mustSplitCode, canOptMap)
-			}
+		comp.emitBlockEnd(blks, b, emitPhi && trackPhi)
+	}
+	comp.emitRunEnd(fn)
+	if mustSplitCode {
+		for sf := range subFnList {
+			comp.emitSubFn(fn, blks, subFnList, sf, mustSplitCode, canOptMap)
 		}
-		comp.emitFuncEnd(fn)
 	}
+	comp.emitFuncEnd(fn)
 }
 
 func (comp *Compilation) emitSubFn(fn *ssa.Function, blks []*ssa.BasicBlock, subFnList []subFnInstrs, sf int, mustSplitCode bool, canOptMap map[string]bool) {
@@ -505,6 +556,7 @@ func (comp *Compilation) emitFuncStart(fn *ssa.Function, blks []*ssa.BasicBlock,
 	posStr := comp.CodePosition(fn.Pos())
 	pName, mName := comp.GetFnNameParts(fn)
 	isPublic := unicode.IsUpper(rune(mName[0])) // TODO check rules for non-ASCII 1st characters and fix
+	comp.addToManifest(fn, posStr, pName, mName)
 	fmt.Fprintln(&LanguageList[l].buffer,
 		LanguageList[l].FuncStart(pName, mName, fn, blks, posStr, isPublic, trackPhi, comp.grMap[fn] || mustSplitCode, canOptMap, reconstruct))
 }
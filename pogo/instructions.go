@@ -245,7 +245,7 @@ func (comp *Compilation) emitInstruction(instruction interface{}, operands []*ss
 
 	case *ssa.Alloc:
 		fmt.Fprintln(&LanguageList[l].buffer,
-			LanguageList[l].Alloc(register, instruction.(*ssa.Alloc).Heap,
+			LanguageList[l].Alloc(register, comp.AllocOnHeap(instruction.(*ssa.Alloc)),
 				instruction.(*ssa.Alloc).Type(), errorInfo)+
 				LanguageList[l].Comment(instruction.(*ssa.Alloc).Comment+" "+comment))
 
@@ -305,14 +305,12 @@ func (comp *Compilation) emitInstruction(instruction interface{}, operands []*ss
 		}
 
 	case *ssa.Slice:
-		// TODO see http://tip.golang.org/doc/go1.2#three_index
-		// TODO add third parameter when SSA code provides it to enable slice instructions to specify a capacity
 		if register == "" {
 			comp.emitComment(comment)
 		} else {
 			fmt.Fprintln(&LanguageList[l].buffer,
 				LanguageList[l].Slice(register, instruction.(*ssa.Slice).X,
-					instruction.(*ssa.Slice).Low, instruction.(*ssa.Slice).High, errorInfo)+
+					instruction.(*ssa.Slice).Low, instruction.(*ssa.Slice).High, instruction.(*ssa.Slice).Max, errorInfo)+
 					LanguageList[l].Comment(comment))
 
 		}
@@ -407,7 +405,7 @@ func (comp *Compilation) emitInstruction(instruction interface{}, operands []*ss
 	case *ssa.DebugRef: // TODO the comment could include the actual Go code
 		debugCode := ""
 		ident, ok := instruction.(*ssa.DebugRef).Expr.(*ast.Ident)
-		if ok {
+		if ok && LanguageList[l].EmitDebugRefs {
 			if ident.Obj != nil {
 				if ident.Obj.Kind == ast.Var {
 					//fmt.Printf("DEBUGref %s (%s) => %s %+v %+v %+v\n", instruction.(*ssa.DebugRef).X.Name(),
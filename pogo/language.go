@@ -67,7 +67,7 @@ type Language interface {
 	MakeSlice(register string, v interface{}, errorInfo string) string
 	MakeChan(register string, v interface{}, errorInfo string) string
 	MakeMap(register string, v interface{}, errorInfo string) string
-	Slice(register string, x, low, high interface{}, errorInfo string) string
+	Slice(register string, x, low, high, max interface{}, errorInfo string) string
 	Index(register string, v1, v2 interface{}, errorInfo string) string
 	RangeCheck(x, i interface{}, length int, errorInfo string) string
 	Field(register string, v interface{}, fNum int, name, errorInfo string, isFunctionName bool) string
@@ -89,6 +89,7 @@ type Language interface {
 	CanInline(v interface{}) bool
 	PhiCode(allTargets bool, targetPhi int, code []ssa.Instruction, errorInfo string) string
 	InitLang(*Compilation, *LanguageEntry) Language
+	JoinLang(Language) // take in what a Language made by InitLang for a unit recorded while emitting its functions
 }
 
 // LanguageEntry holds the static infomation about each of the languages, expect this list to extend as more languages are added.
@@ -101,6 +102,7 @@ type LanguageEntry struct {
 	HeaderConstVarName    string       // The special constant name for a target-specific header.
 	Goruntime             string       // The location of the core implementation go runtime code for this target language.
 	TestFS                string       // the location of the test zipped file system, if present
+	TestBench             string       // the pattern for the benchmarks to run after the tests, if any
 	LineCommentMark       string       // what marks the comment at the end of a line
 	StatementTerminator   string       // what marks the end of a statement, usually ";"
 	PseudoPkgPaths        []string     // paths of packages containing pseudo-functions
@@ -108,12 +110,29 @@ type LanguageEntry struct {
 	files                 []FileOutput // files to write if no errors in compilation
 	GOROOT                string       // static part of the GOROOT path
 	TgtDir                string       // Target directory to write to
+	EmitDebugRefs         bool         // Should the source variable names of DebugRef instructions be emitted?
+	RangeCheckDetail      bool         // Should index out of range panics give the index, length and source position?
+	StreamOutput          bool         // Should each file be written to TgtDir as it is completed, rather than held in memory?
+	MaxNativeDepth        int          // If >0, how deep recursive calls may go on the host stack before yielding to the scheduler.
+	Sizes                 types.Sizes  // The sizes used for the target's memory layout, so also for unsafe.Sizeof etc.
+	ESModule              bool         // Should the public functions be listed as exports, so that JS output can be an ES module?
+	FullTypeInfo          bool         // Should full type information be emitted for every type, even if reflect is not used?
+	ScheduleSeed          int          // If not 0, goroutines are scheduled the same way on every run, with select choices seeded by this
+	Manifest              bool         // Should function metadata be written to a JSON manifest in TgtDir, rather than as inline comments?
+	TrapOverflow          bool         // Should signed integer overflow panic, rather than wrap as Go defines? (for debugging)
+	Workers               int          // If >1, how many goroutines plan and emit the functions of different packages in parallel, with the same output as one.
+	NativeInt64           bool         // Does the target have native 64-bit integers (Haxe cpp, cs or java)? If not, as for JS and Flash, they are emulated.
+	Arena                 bool         // Should the byte values of all objects (not their Haxe values) be held in one flat arena, addressed by integer handles, rather than in an array each?
+	NilCheck              bool         // Should pointers be checked for nil before use, giving a recoverable Go panic rather than a target error? (always with DebugFlag)
+	NoEscape              bool         // Should every Alloc that ssa puts on the heap stay there, rather than re-using stack space when its address does not escape?
 }
 
 // FileOutput provides temporary storage of output file data, pending correct compilation
 type FileOutput struct {
 	filename string
 	data     []byte
+	tempname string // when streaming, the temporary file holding the data
+	isAux    bool   // an auxiliary file, whose name is complete without the language file suffix
 }
 
 // LanguageList holds the languages that can be targeted, and compilation run data
@@ -165,6 +184,10 @@ func (comp *Compilation) FuncPathName(fn *ssa.Function) (path, name string) {
 			if len(pf1) == 2 {
 				pf = pf1[1]
 			} // TODO use GOPATH for names not in std pkgs
+			if strings.HasSuffix(fn.Name(), "$thunk") && len(fn.Params) > 0 {
+				// method expressions T.M and (*T).M give thunks at the same position, so add the receiver type
+				pf += tgoutil.MakeID(":" + fn.Params[0].Type().String())
+			}
 		}
 	}
 	return pf, fn.Name()
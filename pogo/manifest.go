@@ -0,0 +1,67 @@
+// Copyright 2014 Elliott Stoneham and The TARDIS Go Authors
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package pogo
+
+import (
+	"encoding/json"
+
+	"golang.org/x/tools/go/ssa"
+)
+
+// funcManifest holds the metadata for a compiled function that would otherwise only be available in inline comments,
+// which minifiers strip, so that tools can recover it after the target language build.
+type funcManifest struct {
+	Func     string `json:"func"`     // the Go name of the function
+	Target   string `json:"target"`   // the name of the function in the target language
+	Position string `json:"position"` // the source code position
+	PosHash  int    `json:"poshash"`  // the position hash used in the generated code
+	TypeIDs  []int  `json:"typeids"`  // the type ids of the parameters then the results, 0 if there is none
+	fn       *ssa.Function
+}
+
+// addToManifest records a function as it is emitted, if a manifest is required.
+func (comp *Compilation) addToManifest(fn *ssa.Function, posStr, pName, mName string) {
+	l := comp.TargetLang
+	if !LanguageList[l].Manifest {
+		return
+	}
+	if comp.manifest == nil {
+		comp.manifest = make(map[string]*funcManifest)
+	}
+	path, name := comp.FuncPathName(fn)
+	comp.manifest[path+"."+name] = &funcManifest{
+		Func:     fn.String(),
+		Target:   LanguageList[l].LangName(pName, mName),
+		Position: posStr,
+		PosHash:  int(comp.LatestValidPosHash),
+		fn:       fn,
+	}
+}
+
+// emitManifest writes the manifest to manifest.json in the target directory,
+// once all the type ids are known.
+func (comp *Compilation) emitManifest() {
+	if !LanguageList[comp.TargetLang].Manifest {
+		return
+	}
+	for _, fm := range comp.manifest {
+		fm.TypeIDs = []int{}
+		sig := fm.fn.Signature
+		for i := 0; i < sig.Params().Len(); i++ {
+			id, _ := comp.TypesEncountered.At(sig.Params().At(i).Type()).(int)
+			fm.TypeIDs = append(fm.TypeIDs, id)
+		}
+		for i := 0; i < sig.Results().Len(); i++ {
+			id, _ := comp.TypesEncountered.At(sig.Results().At(i).Type()).(int)
+			fm.TypeIDs = append(fm.TypeIDs, id)
+		}
+	}
+	data, err := json.MarshalIndent(comp.manifest, "", "\t") // map keys are sorted, so the file is always in the same order
+	if err != nil {
+		comp.LogError("Unable to encode the manifest", "pogo", err)
+		return
+	}
+	comp.WriteAsAuxFile("manifest.json", string(data)+"\n")
+}
@@ -21,20 +21,83 @@ func writeIfChanged(filename string, data []byte) error {
 // WriteAsClass writes the contents of the buffer as a given class file name.
 func (comp *Compilation) WriteAsClass(name, code string) {
 	l := comp.TargetLang
-	if LanguageList[l].files == nil {
-		LanguageList[l].files = make([]FileOutput, 0, 100)
-	}
 	_, err := LanguageList[l].buffer.WriteString(code)
 	if err != nil {
 		panic(err)
 	}
-	var data = make([]byte, LanguageList[l].buffer.Len())
-	copy(data, LanguageList[l].buffer.Bytes())
-	LanguageList[l].files = append(LanguageList[l].files, FileOutput{name, data})
-	LanguageList[l].buffer.Reset()
+	comp.keepBuffer(name)
 	comp.emitFileStart()
 }
 
+// keepBuffer keeps the code in the buffer as the named file, streaming it if required, then empties the buffer.
+func (comp *Compilation) keepBuffer(name string) {
+	l := comp.TargetLang
+	if LanguageList[l].files == nil {
+		LanguageList[l].files = make([]FileOutput, 0, 100)
+	}
+	if LanguageList[l].StreamOutput {
+		comp.streamFile(name, LanguageList[l].buffer.Bytes())
+	} else {
+		var data = make([]byte, LanguageList[l].buffer.Len())
+		copy(data, LanguageList[l].buffer.Bytes())
+		LanguageList[l].files = append(LanguageList[l].files, FileOutput{filename: name, data: data})
+	}
+	LanguageList[l].buffer.Reset()
+}
+
+// WriteAsAuxFile writes code that is not in the target language, to the file name given, in the target directory.
+func (comp *Compilation) WriteAsAuxFile(name, code string) {
+	l := comp.TargetLang
+	LanguageList[l].files = append(LanguageList[l].files, FileOutput{filename: name, data: []byte(code), isAux: true})
+}
+
+// streamFile writes the data to a temporary file in the target directory, to be renamed if compilation succeeds.
+func (comp *Compilation) streamFile(name string, data []byte) {
+	l := comp.TargetLang
+	if comp.targetDir() != nil {
+		return // error already logged
+	}
+	tmp, err := ioutil.TempFile(LanguageList[l].TgtDir, name+"-")
+	if err == nil {
+		_, err = tmp.Write(data)
+		if e := tmp.Close(); err == nil {
+			err = e
+		}
+		if err != nil {
+			os.Remove(tmp.Name())
+		}
+	}
+	if err != nil {
+		comp.LogError("Unable to write temporary output file", "pogo", err)
+		return
+	}
+	LanguageList[l].files = append(LanguageList[l].files, FileOutput{filename: name, tempname: tmp.Name()})
+}
+
+// removeStreamedFiles cleans up the temporary files from streaming, if compilation fails.
+func (comp *Compilation) removeStreamedFiles() {
+	for _, fo := range LanguageList[comp.TargetLang].files {
+		if fo.tempname != "" {
+			os.Remove(fo.tempname)
+		}
+	}
+}
+
+// renameIfChanged moves the temporary file into place, unless the existing file has the same contents.
+func renameIfChanged(filename, tempname string) error {
+	content, err := ioutil.ReadFile(filename)
+	if err == nil {
+		data, err := ioutil.ReadFile(tempname)
+		if err != nil {
+			return err
+		}
+		if bytes.Equal(content, data) {
+			return os.Remove(tempname)
+		}
+	}
+	return os.Rename(tempname, filename)
+}
+
 func (comp *Compilation) targetDir() error {
 	if err := os.Mkdir(LanguageList[comp.TargetLang].TgtDir, os.ModePerm); err != nil {
 		if !os.IsExist(err) { // no problem if it already exists
@@ -54,17 +117,23 @@ func (comp *Compilation) writeFiles() {
 	err := comp.targetDir()
 	if err == nil {
 		for _, fo := range LanguageList[l].files {
-			err = writeIfChanged(
-				LanguageList[comp.TargetLang].TgtDir+
-					string(os.PathSeparator)+fo.filename+
-					LanguageList[l].FileTypeSuffix(),
-				fo.data)
+			filename := LanguageList[comp.TargetLang].TgtDir +
+				string(os.PathSeparator) + fo.filename
+			if !fo.isAux {
+				filename += LanguageList[l].FileTypeSuffix()
+			}
+			if fo.tempname != "" {
+				err = renameIfChanged(filename, fo.tempname)
+			} else {
+				err = writeIfChanged(filename, fo.data)
+			}
 			if err != nil {
 				break
 			}
 		}
 	}
 	if err != nil {
+		comp.removeStreamedFiles()
 		comp.LogError("Unable to write output file", "pogo", err)
 	}
 }
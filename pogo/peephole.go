@@ -178,6 +178,21 @@ func (comp *Compilation) peepholeFindOpt(instrs []ssa.Instruction) (optName, reg
 			}
 		}
 
+	case *ssa.MakeInterface:
+		// a value boxed into an interface, only to be asserted straight back to its own type
+		if len(instrs) != 2 {
+			return // fail
+		}
+		ta, isTA := instrs[1].(*ssa.TypeAssert)
+		if !isTA || ta.CommaOk || ta.X != instrs[0].(ssa.Value) ||
+			len(*instrs[0].(*ssa.MakeInterface).Referrers()) != 1 ||
+			!types.Identical(ta.AssertedType, instrs[0].(*ssa.MakeInterface).X.Type()) {
+			return // fail
+		}
+		optName = "elideBox"
+		regName = comp.RegisterName(ta)
+		return // success
+
 	case *ssa.Phi:
 		if len(instrs) == 0 {
 			return // fail
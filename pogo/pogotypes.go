@@ -54,7 +54,11 @@ func (comp *Compilation) initTypes() {
 }
 
 // LogTypeUse : As the code generator encounters new types it logs them here, returning a string of the ID for insertion into the code.
+// In a unit emitted on a worker, the string is a mark that is replaced by the ID when the unit is joined.
 func (comp *Compilation) LogTypeUse(t types.Type) string {
+	if comp.unitOf != nil {
+		return comp.typeMark(t)
+	}
 	r := comp.TypesEncountered.At(t)
 	if r != nil {
 		return fmt.Sprintf("%d", r)
@@ -162,9 +166,53 @@ func (comp *Compilation) visitAllTypes() {
 	}
 }
 
+// markTypeInfoNeeded works out which types need full type information at run-time.
+// If reflect is in the program every type may be reflected upon, so all of them do.
+// Otherwise only the types of interface values need more than their name, kind and size:
+// dynamic types for method dispatch, and interface types for type assertion.
+func (comp *Compilation) markTypeInfoNeeded() {
+	comp.typeInfoNeeded = nil
+	if LanguageList[comp.TargetLang].FullTypeInfo || comp.rootProgram.ImportedPackage("reflect") != nil {
+		return
+	}
+	comp.typeInfoNeeded = make(map[int]bool)
+	need := func(t types.Type) {
+		if id, ok := comp.TypesEncountered.At(t).(int); ok {
+			comp.typeInfoNeeded[id] = true
+		}
+	}
+	for fn := range comp.fnMap {
+		for _, b := range fn.Blocks {
+			for _, instr := range b.Instrs {
+				switch instr := instr.(type) {
+				case *ssa.MakeInterface:
+					need(instr.X.Type())
+					need(instr.Type())
+				case *ssa.ChangeInterface:
+					need(instr.X.Type())
+					need(instr.Type())
+				case *ssa.TypeAssert:
+					need(instr.X.Type())
+					need(instr.AssertedType)
+				}
+			}
+		}
+	}
+}
+
+// TypeInfoNeeded reports if the type needs full type information at run-time, rather than a minimal entry.
+func (comp *Compilation) TypeInfoNeeded(t types.Type) bool {
+	if comp.typeInfoNeeded == nil {
+		return true
+	}
+	id, ok := comp.TypesEncountered.At(t).(int)
+	return !ok || comp.typeInfoNeeded[id]
+}
+
 // Wrapper for target language emitTypeInfo()
 func (comp *Compilation) emitTypeInfo() {
 	comp.visitAllTypes()
+	comp.markTypeInfoNeeded()
 	l := comp.TargetLang
 
 	if len(comp.LibListNoDCE) > 0 { // output target lang type to access named object
@@ -173,7 +221,7 @@ func (comp *Compilation) emitTypeInfo() {
 				if comp.TypesEncountered.At(k).(int) == t {
 					switch k.(type) {
 					case *types.Named:
-						if k.(*types.Named).Obj().Exported() {
+						if _, isEnum := comp.enumTypes[k.(*types.Named)]; k.(*types.Named).Obj().Exported() && !isEnum {
 							fmt.Fprintln(&LanguageList[l].buffer,
 								LanguageList[l].TypeStart(k.(*types.Named), k.String()))
 							//fmt.Fprintln(&LanguageList[l].buffer,
@@ -185,5 +233,7 @@ func (comp *Compilation) emitTypeInfo() {
 		}
 	}
 
+	comp.emitEnumTypes()
+
 	fmt.Fprintln(&LanguageList[l].buffer, LanguageList[l].EmitTypeInfo())
 }
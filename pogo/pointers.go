@@ -6,6 +6,7 @@ package pogo
 
 import (
 	"fmt"
+	"go/token"
 	"go/types"
 
 	"golang.org/x/tools/go/ssa"
@@ -51,3 +52,50 @@ func showIndirectValue(v interface{}) string {
 		return fmt.Sprintf("%s", v.(ssa.Value).String())
 	}
 }
+
+// AllocOnHeap reports if the given Alloc must be made on the heap.
+// ssa marks any local whose address is taken as a heap allocation, but if
+// that address is only ever used within the function to load, store or
+// reach into the value it cannot escape, so the allocation may re-use
+// space on the stack rather than creating a new Object each time.
+func (comp *Compilation) AllocOnHeap(a *ssa.Alloc) bool {
+	if !a.Heap {
+		return false
+	}
+	if LanguageList[comp.TargetLang].NoEscape {
+		return true
+	}
+	return addrEscapes(a)
+}
+
+// addrEscapes conservatively reports if the address v may outlive the current function frame.
+func addrEscapes(v ssa.Value) bool {
+	refs := v.Referrers()
+	if refs == nil {
+		return true
+	}
+	for _, r := range *refs {
+		switch r.(type) {
+		case *ssa.DebugRef:
+		case *ssa.Store:
+			if r.(*ssa.Store).Val == v {
+				return true
+			}
+		case *ssa.UnOp:
+			if r.(*ssa.UnOp).Op != token.MUL {
+				return true
+			}
+		case *ssa.FieldAddr:
+			if addrEscapes(r.(*ssa.FieldAddr)) {
+				return true
+			}
+		case *ssa.IndexAddr:
+			if addrEscapes(r.(*ssa.IndexAddr)) {
+				return true
+			}
+		default:
+			return true
+		}
+	}
+	return false
+}
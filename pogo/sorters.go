@@ -1,6 +1,8 @@
 package pogo
 
 import (
+	"go/constant"
+	"go/token"
 	"go/types"
 	"sort"
 
@@ -42,6 +44,47 @@ func (comp *Compilation) fnMapSorted() []*ssa.Function {
 // TypeSorter is a type to allow types to be sorted
 type TypeSorter []types.Type
 
-func (a TypeSorter) Len() int           { return len(a) }
-func (a TypeSorter) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
-func (a TypeSorter) Less(i, j int) bool { return a[i].String() < a[j].String() }
+func (a TypeSorter) Len() int      { return len(a) }
+func (a TypeSorter) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+func (a TypeSorter) Less(i, j int) bool {
+	si, sj := a[i].String(), a[j].String()
+	if si != sj {
+		return si < sj
+	}
+	return typePos(a[i]) < typePos(a[j]) // different types can share a string, e.g. local types with the same name
+}
+
+// typePos returns the source position of the first named type or struct field within t, if there is one
+func typePos(t types.Type) token.Pos {
+	switch tt := t.(type) {
+	case *types.Named:
+		return tt.Obj().Pos()
+	case *types.Pointer:
+		return typePos(tt.Elem())
+	case *types.Slice:
+		return typePos(tt.Elem())
+	case *types.Array:
+		return typePos(tt.Elem())
+	case *types.Chan:
+		return typePos(tt.Elem())
+	case *types.Map:
+		if p := typePos(tt.Key()); p != token.NoPos {
+			return p
+		}
+		return typePos(tt.Elem())
+	case *types.Struct:
+		if tt.NumFields() > 0 {
+			return tt.Field(0).Pos()
+		}
+	}
+	return token.NoPos
+}
+
+// constSorter allows named constants to be sorted by value
+type constSorter []*ssa.NamedConst
+
+func (a constSorter) Len() int      { return len(a) }
+func (a constSorter) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+func (a constSorter) Less(i, j int) bool {
+	return constant.Compare(a[i].Value.Value, token.LSS, a[j].Value.Value)
+}
@@ -0,0 +1,153 @@
+// Copyright 2014 Elliott Stoneham and The TARDIS Go Authors
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package pogo
+
+import (
+	"bytes"
+	"fmt"
+	"go/types"
+	"strconv"
+	"sync"
+
+	"golang.org/x/tools/go/ssa"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+//------------------------------------------------------------------------------------------------------------
+// If LanguageEntry.Workers > 1, the functions are planned and emitted on that many goroutines.
+// Each run of functions from one package is a unit: a copy of the Compilation with a LanguageList entry of its own,
+// so with its own buffer, files and Language. The units are joined back into the Compilation in order,
+// so that the output is the same as if the functions had been emitted serially.
+//------------------------------------------------------------------------------------------------------------
+
+// typeMarks gives each type used by the units a mark, to stand in for its type ID in their code.
+// Type IDs are given in the order that the types are first used, which is only known as the units are joined.
+type typeMarks struct {
+	sync.Mutex
+	marks typeutil.Map // the mark of each type seen so far
+	types []types.Type // the type of each mark
+}
+
+// typeMark returns the text that stands in for the ID of the type in the code of a unit, until it is joined.
+func (comp *Compilation) typeMark(t types.Type) string {
+	tm := comp.unitOf.marks
+	tm.Lock()
+	m, seen := tm.marks.At(t).(int)
+	if !seen {
+		m = len(tm.types)
+		tm.marks.Set(t, m)
+		tm.types = append(tm.types, t)
+	}
+	tm.Unlock()
+	if !comp.markSeen[m] {
+		comp.markSeen[m] = true
+		comp.marksUsed = append(comp.marksUsed, m)
+	}
+	return "\x00" + strconv.Itoa(m) + "\x00" // a NUL is never otherwise in the code, as string constants are escaped
+}
+
+// unmark replaces the type marks in the code of a unit with their type IDs.
+func unmark(code []byte, ids map[int]string) []byte {
+	parts := bytes.Split(code, []byte{0})
+	for i := 1; i < len(parts); i += 2 {
+		m, err := strconv.Atoi(string(parts[i]))
+		if err != nil {
+			panic(fmt.Errorf("pogo.unmark() invalid type mark: %q", parts[i]))
+		}
+		parts[i] = []byte(ids[m])
+	}
+	return bytes.Join(parts, nil)
+}
+
+// emitUnits plans and emits the functions in parallel, as a unit for each run of them from one package, then joins the units in order.
+func (comp *Compilation) emitUnits(fns []*ssa.Function) {
+	runs := [][]*ssa.Function{} // synthetic functions without a package are in runs of their own
+	for i, f := range fns {
+		if i == 0 || f.Pkg != fns[i-1].Pkg {
+			runs = append(runs, nil)
+		}
+		runs[len(runs)-1] = append(runs[len(runs)-1], f)
+	}
+	comp.marks = &typeMarks{}
+	units := comp.newUnits(len(runs))
+	work := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < LanguageList[comp.TargetLang].Workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for u := range work {
+				for _, f := range runs[u] {
+					units[u].emitFunc(f, units[u].planFunc(f))
+				}
+			}
+		}()
+	}
+	for u := range runs {
+		work <- u
+	}
+	close(work)
+	wg.Wait()
+	for _, u := range units {
+		comp.joinUnit(u)
+	}
+	comp.marks = nil
+}
+
+// newUnits makes n units of the Compilation, each with a LanguageList entry that only differs in where the code goes.
+func (comp *Compilation) newUnits(n int) []*Compilation {
+	entry := LanguageList[comp.TargetLang]
+	entry.buffer = bytes.Buffer{}
+	entry.files = nil
+	entry.StreamOutput = false // the files are streamed, if required, as the units are joined
+	units := make([]*Compilation, n)
+	languageListAppendMutex.Lock()
+	for i := range units {
+		u := *comp
+		u.unitOf = comp
+		u.TargetLang = len(LanguageList)
+		u.warnings, u.manifest, u.hadErrors = nil, nil, false
+		u.markSeen = make(map[int]bool)
+		units[i] = &u
+		LanguageList = append(LanguageList, entry)
+	}
+	languageListAppendMutex.Unlock()
+	for _, u := range units { // only once all are appended, as an append may move LanguageList
+		LanguageList[u.TargetLang].Language = entry.InitLang(u, &LanguageList[u.TargetLang])
+	}
+	return units
+}
+
+// joinUnit adds the code, files, messages and other records of a unit to the Compilation, then recycles the unit.
+func (comp *Compilation) joinUnit(u *Compilation) {
+	ids := make(map[int]string)
+	for _, m := range u.marksUsed { // in the order the unit first used them, as a serial run would
+		ids[m] = comp.LogTypeUse(comp.marks.types[m])
+	}
+	l, ul := comp.TargetLang, u.TargetLang
+	for _, f := range LanguageList[ul].files {
+		if f.isAux {
+			LanguageList[l].files = append(LanguageList[l].files, f)
+			continue
+		}
+		LanguageList[l].buffer.Write(unmark(f.data, ids))
+		comp.keepBuffer(f.filename)
+	}
+	LanguageList[l].buffer.Write(unmark(LanguageList[ul].buffer.Bytes(), ids))
+	for _, msg := range u.heldMessages {
+		comp.giveMessage(msg)
+	}
+	comp.warnings = append(comp.warnings, u.warnings...)
+	comp.hadErrors = comp.hadErrors || u.hadErrors
+	for k, fm := range u.manifest {
+		if comp.manifest == nil {
+			comp.manifest = make(map[string]*funcManifest)
+		}
+		comp.manifest[k] = fm
+	}
+	comp.LatestValidPosHash, comp.previousErrorInfo = u.LatestValidPosHash, u.previousErrorInfo
+	LanguageList[l].JoinLang(LanguageList[ul].Language)
+	u.Recycle()
+}
@@ -53,6 +53,7 @@ N	build [N]aive SSA form: don't replace local loads/stores with registers.
 */
 
 var testFlag = flag.Bool("test", false, "Loads test code (*_test.go) for imported packages.")
+var benchFlag = flag.String("bench", "", "With -test, also run the benchmarks matching this regular expression, timed on the target.")
 var loadTestZipFS = false
 
 const testFS = "tgotestfs.zip"
@@ -72,6 +73,18 @@ var debugFlag = flag.Bool("debug", false, "Instrument the code to enable debuggi
 var traceFlag = flag.Bool("trace", false, "Output trace information for every block visited (warning: huge output)")
 var buidTags = flag.String("tags", "", "build tags separated by spaces")
 var tgoroot = flag.String("tgoroot", "", "set goroot to the given value")
+var streamFlag = flag.Bool("stream", false, "Write each output file as it is completed, to reduce memory use on large builds")
+var depthFlag = flag.Int("depth", 0, "If >0, the depth of calls on the host stack after which recursive functions yield to the scheduler, to avoid stack overflow")
+var fullTypesFlag = flag.Bool("fulltypes", false, "Emit full type information for every type, as needed by reflect, even if the reflect package is not used")
+var scheduleSeedFlag = flag.Int("schedseed", 0, "If not 0, schedule goroutines the same way on every run, with a virtual clock and select choices seeded by this value (for testing)")
+var esModuleFlag = flag.Bool("esmodule", false, "Also write tardis/go-exports.js, the export block to append to the JS output to make it an ES module")
+var manifestFlag = flag.Bool("manifest", false, "Write the position and type ids of each function to tardis/manifest.json, rather than as inline comments that minifiers strip")
+var trapOverflowFlag = flag.Bool("trapoverflow", false, "Panic on signed integer overflow, rather than wrapping as Go defines (for debugging code that assumes no overflow)")
+var native64Flag = flag.Bool("native64", false, "Use the native 64-bit integers of the Haxe cpp, cs or java targets for int64 arithmetic, rather than the emulation needed for JS and Flash")
+var arenaFlag = flag.Bool("arena", false, "Hold the byte values of all Go objects in one flat arena, addressed by integer handles, rather than in an array each; strings, pointers and other Haxe values are still held by each object, the arena is never compacted, and Haxe -D fullunsafe or -D abstractobjects are rejected")
+var nilCheckFlag = flag.Bool("nilcheck", false, "Check pointers for nil before they are used, so that a nil pointer dereference raises a Go panic that can be recovered (always done with -debug)")
+var noEscapeFlag = flag.Bool("noescape", false, "Allocate every local whose address is taken on the heap, as ssa marks it, rather than re-using stack space for those that do not escape (to measure what that saves)")
+var workersFlag = flag.Int("workers", 0, "If >1, the number of goroutines that compile the functions of different packages in parallel, the output is the same as with one")
 
 //var modeFlag = ssa.BuilderModeFlag(flag.CommandLine, "build", 0)
 var modeFlag = ssa.BuilderMode(0)
@@ -152,6 +165,21 @@ func doTestable(args []string) error {
 	if e != nil {
 		return e
 	}
+	pogo.LanguageList[langEntry].EmitDebugRefs = *debugFlag // source variable names are only required for debugging
+	pogo.LanguageList[langEntry].RangeCheckDetail = *debugFlag
+	pogo.LanguageList[langEntry].StreamOutput = *streamFlag
+	pogo.LanguageList[langEntry].MaxNativeDepth = *depthFlag
+	pogo.LanguageList[langEntry].ESModule = *esModuleFlag
+	pogo.LanguageList[langEntry].FullTypeInfo = *fullTypesFlag
+	pogo.LanguageList[langEntry].ScheduleSeed = *scheduleSeedFlag
+	pogo.LanguageList[langEntry].TestBench = *benchFlag
+	pogo.LanguageList[langEntry].Manifest = *manifestFlag
+	pogo.LanguageList[langEntry].TrapOverflow = *trapOverflowFlag
+	pogo.LanguageList[langEntry].Workers = *workersFlag
+	pogo.LanguageList[langEntry].NativeInt64 = *native64Flag
+	pogo.LanguageList[langEntry].Arena = *arenaFlag
+	pogo.LanguageList[langEntry].NilCheck = *nilCheckFlag
+	pogo.LanguageList[langEntry].NoEscape = *noEscapeFlag
 
 	// TODO(adonovan): make go/types choose its default Sizes from
 	// build.Default or a specified *build.Context.
@@ -171,9 +199,14 @@ func doTestable(args []string) error {
 
 	conf.Build.BuildTags = strings.Split(*buidTags, " ")
 
-	conf.TypeChecker.Sizes = &types.StdSizes{ // must equal haxe.haxeStdSizes when (!*runFlag)
-		MaxAlign: 8,
-		WordSize: wordSize,
+	if *runFlag || pogo.LanguageList[langEntry].Sizes == nil {
+		conf.TypeChecker.Sizes = &types.StdSizes{
+			MaxAlign: 8,
+			WordSize: wordSize,
+		}
+	} else {
+		// so that unsafe.Sizeof, Offsetof & Alignof are folded to match the target's memory layout
+		conf.TypeChecker.Sizes = pogo.LanguageList[langEntry].Sizes
 	}
 
 	var mode ssa.BuilderMode
@@ -257,8 +290,10 @@ func doTestable(args []string) error {
 	//fmt.Println("DEBUG GOPATH", conf.Build.GOPATH)
 	//fmt.Println("DEBUG GOROOT", conf.Build.GOROOT)
 
+	testPath := ""
 	if *testFlag {
-		conf.ImportWithTests(args[0]) // assumes you give the full cannonical name of the package to test
+		testPath = args[0]
+		conf.ImportWithTests(testPath) // assumes you give the full cannonical name of the package to test
 		args = args[1:]
 	}
 
@@ -286,8 +321,7 @@ func doTestable(args []string) error {
 	}
 
 	// Create and build SSA-form program representation.
-	modeFlag |= mode | ssa.SanityCheckFunctions
-	prog := ssautil.CreateProgram(iprog, modeFlag)
+	prog := ssautil.CreateProgram(iprog, modeFlag|mode|ssa.SanityCheckFunctions) // not saved in modeFlag, as later compilations may not be debugging
 
 	prog.Build()
 
@@ -297,9 +331,9 @@ func doTestable(args []string) error {
 
 	testFSname := ""
 	if *testFlag {
-		// If -test, run all packages' tests.
-		if len(pkgs) == 1 {
-			main = prog.CreateTestMainPackage(pkgs[0]) // as per #51
+		// If -test, run the tests of the package given, rather than those of the runtime packages also imported.
+		if len(args) == 0 && iprog.Imported[testPath] != nil {
+			main = prog.CreateTestMainPackage(prog.Package(iprog.Imported[testPath].Pkg)) // as per #51
 		} else {
 			return fmt.Errorf("only one package can be tested at a time")
 		}
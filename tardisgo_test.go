@@ -1,8 +1,15 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
 	"os"
 	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
 	"testing"
 )
 
@@ -25,7 +32,7 @@ func TestCore(t *testing.T) {
 
 	// any Haxe output would signal an error
 	if len(out) > 0 {
-		t.Errorf(string(out))
+		t.Error(string(out))
 	}
 
 	err = os.Chdir("../..")
@@ -34,4 +41,930 @@ func TestCore(t *testing.T) {
 	}
 }
 
+// enter changes to dir, returning the function to defer that changes back,
+// so that a test which stops part way does not leave the tests that follow in the wrong directory.
+func enter(t testing.TB, dir string) func() {
+	top, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.Chdir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return func() {
+		err := os.Chdir(top)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// transpile compiles file, or a package path, from the current directory into a fresh tardis directory,
+// with each of flags set only while it does so.
+func transpile(t testing.TB, file string, flags ...*bool) {
+	os.RemoveAll("tardis")
+	for _, f := range flags {
+		*f = true
+	}
+	err := doTestable([]string{file})
+	for _, f := range flags {
+		*f = false // so that later tests compile as usual
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// haxeInterp is the command that runs the transpiled program in the Haxe interpreter, giving Haxe any extra args.
+func haxeInterp(args ...string) *exec.Cmd {
+	args = append([]string{"-main", "tardis.Go", "-cp", "tardis"}, args...)
+	return exec.Command("haxe", append(args, "--interp")...)
+}
+
+// transpileAndRun compiles file, in dir, with flags set, and returns what it writes when run in the Haxe interpreter.
+func transpileAndRun(t *testing.T, dir, file string, flags ...*bool) []byte {
+	defer enter(t, dir)()
+	transpile(t, file, flags...)
+	out, err := haxeInterp().CombinedOutput()
+	if err != nil {
+		t.Error(err)
+	}
+	return out
+}
+
+// readFile returns the contents of the named file, which the test cannot go on without.
+func readFile(t testing.TB, name string) string {
+	data, err := ioutil.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+// readTardis returns the contents of every file in the tardis directory, by name.
+func readTardis(t testing.TB) map[string]string {
+	files, err := ioutil.ReadDir("tardis")
+	if err != nil {
+		t.Fatal(err)
+	}
+	contents := make(map[string]string)
+	for _, f := range files {
+		contents[f.Name()] = readFile(t, "tardis/"+f.Name())
+	}
+	return contents
+}
+
+// compileFails checks that file, in dir, is rejected by the type checker with an error matching pattern.
+// Such files have a build tag, which only stops the go tool from building them.
+func compileFails(t *testing.T, dir, file, pattern string) {
+	defer enter(t, dir)()
+
+	stderr := os.Stderr // where the type checker reports its errors
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stderr = w
+	err = doTestable([]string{file})
+	os.Stderr = stderr
+	w.Close()
+	msgs, _ := ioutil.ReadAll(r)
+	if err == nil {
+		t.Errorf("%s compiled", file)
+	} else if !regexp.MustCompile(pattern).Match(msgs) {
+		t.Errorf("%s did not fail with %q, but with: %v\n%s", file, pattern, err, msgs)
+	}
+}
+
+// trimLines removes the spaces at either end of each line of out, such as the one println may leave after the last argument
+func trimLines(out []byte) string {
+	lines := strings.Split(string(out), "\n")
+	for i := range lines {
+		lines[i] = strings.TrimSpace(lines[i])
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestSelectEmpty(t *testing.T) {
+	defer enter(t, "tests/selectempty")()
+	transpile(t, "selectempty.go")
+
+	// the program must deadlock, rather than loop forever, once the other goroutine has done its work
+	out, err := haxeInterp().CombinedOutput()
+	if err == nil {
+		t.Error("select{} did not stop the program")
+	}
+	if !strings.Contains(string(out), "working 2\n") {
+		t.Errorf("goroutine did not run alongside select{}: %s", out)
+	}
+	if !strings.Contains(string(out), "all goroutines are asleep - deadlock!") {
+		t.Errorf("deadlock not detected: %s", out)
+	}
+}
+
+func TestDebugRef(t *testing.T) {
+	defer enter(t, "tests/debugref")()
+
+	transpile(t, "debugref.go", debugFlag)
+	code := readFile(t, "tardis/Go_main_sum.hx")
+	for _, name := range []string{"limit", "total", "counter"} {
+		if !strings.Contains(code, `this.setDebugVar("`+name+`",`) {
+			t.Errorf("no debug annotation for local variable %s", name)
+		}
+	}
+
+	// without -debug the same program must not carry the annotations
+	transpile(t, "debugref.go")
+	plain := readFile(t, "tardis/Go_main_sum.hx")
+	if strings.Contains(plain, "setDebugVar") {
+		t.Error("debug annotations emitted without -debug")
+	}
+	if plain == code {
+		t.Error("the code emitted with and without -debug is the same")
+	}
+}
+
+func TestRangeCheckDetail(t *testing.T) {
+	defer enter(t, "tests/rangecheck")()
+
+	transpile(t, "rangecheck.go")
+	code := readFile(t, "tardis/Go_main_main.hx")
+	if !strings.Contains(code, "Scheduler.wraprangechk(") ||
+		strings.Contains(code, "Scheduler.wraprangechkd(") {
+		t.Error("lean range check not emitted when not debugging")
+	}
+
+	transpile(t, "rangecheck.go", debugFlag)
+	code = readFile(t, "tardis/Go_main_main.hx")
+	if !strings.Contains(code, "Scheduler.wraprangechkd(") {
+		t.Error("detailed range check not emitted when debugging")
+	}
+
+	out, err := haxeInterp().CombinedOutput()
+	if err == nil {
+		t.Error("index out of range did not stop the program")
+	}
+	if !strings.Contains(string(out), "index out of range [5] with length 3") {
+		t.Errorf("index out of range gave: %s", out)
+	}
+}
+
+func TestTypeIDs(t *testing.T) {
+	out := transpileAndRun(t, "tests/typeids", "typeids.go")
+
+	// any Haxe output would signal an error
+	if len(out) > 0 {
+		t.Error(string(out))
+	}
+}
+
+func TestOverload(t *testing.T) {
+	out := transpileAndRun(t, "tests/overload", "overload.go")
+	if trimLines(out) != "FF\n00FF\n" {
+		t.Errorf("unexpected output from the overloaded Haxe function: %s", out)
+	}
+}
+
+func TestConstStringFold(t *testing.T) {
+	defer enter(t, "tests/conststring")()
+	transpile(t, "conststring.go")
+
+	code := readFile(t, "tardis/Go_main_main.hx")
+	if !strings.Contains(code, `Console.println(["start middle end",`) {
+		t.Error("constant string concatenation not emitted as a single literal")
+	}
+	if !strings.Contains(code, `"start "+_t`) {
+		t.Error("non-constant string concatenation not emitted as a runtime concatenation")
+	}
+	goCode := readFile(t, "tardis/Go.hx")
+	if !strings.Contains(goCode, `main_WWhole:String = "start middle end, nacl";`) {
+		t.Error("named constant concatenation across packages not emitted as a single literal")
+	}
+}
+
+func TestElideBox(t *testing.T) {
+	defer enter(t, "tests/elidebox")()
+	transpile(t, "elidebox.go")
+
+	code := readFile(t, "tardis/Go_main_unboxed.hx")
+	if !strings.Contains(code, "PEEPHOLE OPTIMIZATION elideBox") ||
+		strings.Contains(code, "new Interface(") {
+		t.Error("interface box asserted straight back to its own type not elided")
+	}
+	code = readFile(t, "tardis/Go_main_kept.hx")
+	if strings.Contains(code, "PEEPHOLE OPTIMIZATION elideBox") ||
+		!strings.Contains(code, "new Interface(") {
+		t.Error("interface box that is stored has been elided")
+	}
+}
+
+func TestComplexConstFold(t *testing.T) {
+	defer enter(t, "tests/complexconst")()
+	transpile(t, "complexconst.go")
+
+	code := readFile(t, "tardis/Go_main_main.hx")
+	if !strings.Contains(code, `Console.println([Console.printFloat(Force.toFloat(1.5)),Console.printFloat(Force.toFloat(2.25)),Console.printFloat(Force.toFloat(3)),`) {
+		t.Error("real() and imag() of a complex constant not emitted as float literals")
+	}
+	goCode := readFile(t, "tardis/Go.hx")
+	if !strings.Contains(goCode, `main_RRe:Float = 1.5;`) ||
+		!strings.Contains(goCode, `main_IIm:Float = 2.25;`) {
+		t.Error("named constants from real() and imag() not emitted as float literals")
+	}
+}
+
+func TestUnsafeSizeof(t *testing.T) {
+	defer enter(t, "tests/sizeof")()
+	transpile(t, "sizeof.go")
+
+	// the constants must match the Object size and field offset used for the memory layout
+	goCode := readFile(t, "tardis/Go.hx")
+	if !strings.Contains(goCode, "main_l:Pointer=Pointer.make(Object.make(20)") {
+		t.Error("unexpected Object size for the layout struct")
+	}
+	if !strings.Contains(goCode, "main_a:Pointer=Pointer.make(Object.make(32) /* Array: [8]int32 */)") {
+		t.Error("constant expression array size not evaluated")
+	}
+	code := readFile(t, "tardis/Go_main_main.hx")
+	if !strings.Contains(code, "off=8+Go.main_l.off;") {
+		t.Error("unexpected field offset for the layout struct")
+	}
+	if !strings.Contains(code, "Console.println([Console.printUint(Force.toUint32(Force.toInt( #if js untyped __js__(\"0x14\")") ||
+		!strings.Contains(code, "#else 0x14 #end ))),Console.printUint(Force.toUint32(Force.toInt( #if js untyped __js__(\"0x8\")") {
+		t.Error("unsafe.Sizeof or unsafe.Offsetof do not match the memory layout")
+	}
+	if !strings.Contains(code, "Console.println([8,Console.printUint(Force.toUint32(Force.toInt( #if js untyped __js__(\"0x20\")") {
+		t.Error("len or unsafe.Sizeof of a constant expression array size do not match")
+	}
+}
+
+func TestSliceBounds(t *testing.T) {
+	defer enter(t, "tests/slicebounds")()
+	transpile(t, "slicebounds.go")
+
+	out, err := haxeInterp().CombinedOutput()
+	if err != nil {
+		t.Error(err)
+	}
+	if trimLines(out) != "3 3 bc 0 3\n" {
+		t.Errorf("valid slice bounds gave: %s", out)
+	}
+
+	// each invalid case is selected by a Haxe define
+	for c := 1; c <= 9; c++ {
+		out, err := haxeInterp("-D", fmt.Sprintf("slice%d", c)).CombinedOutput()
+		if err == nil {
+			t.Errorf("invalid slice bounds case %d did not stop the program", c)
+		}
+		if !strings.Contains(string(out), "slice bounds out of range") {
+			t.Errorf("invalid slice bounds case %d gave: %s", c, out)
+		}
+	}
+}
+
+func TestRecursion(t *testing.T) {
+	*depthFlag = 100 // recursive calls deeper than this yield to the scheduler
+	defer func() { *depthFlag = 0 }()
+	out := transpileAndRun(t, "tests/recursion", "recursion.go")
+	if trimLines(out) != "873876091\n" { // fib(100000) as a uint32
+		t.Errorf("deep recursion gave: %s", out)
+	}
+}
+
+func TestStreamOutput(t *testing.T) {
+	defer enter(t, "tests/typeids")() // a program of more than one package
+
+	transpile(t, "typeids.go")
+	buffered := readTardis(t)
+	transpile(t, "typeids.go", streamFlag)
+	streamed := readTardis(t)
+
+	if len(buffered) == 0 || len(buffered) != len(streamed) {
+		t.Errorf("buffered mode wrote %d files, streaming mode wrote %d", len(buffered), len(streamed))
+	}
+	for name, data := range buffered {
+		if streamed[name] != data {
+			t.Errorf("streaming mode output differs for %s", name)
+		}
+	}
+}
+
+func TestDeterministicTypeInfo(t *testing.T) {
+	defer enter(t, "tests/typeids")() // has distinct types that share a type string
+
+	var first map[string]string
+	for run := 0; run < 3; run++ {
+		transpile(t, "typeids.go")
+		contents := make(map[string]string)
+		for name, data := range readTardis(t) {
+			if strings.HasPrefix(name, "Type") || name == "Tgotypes.hx" {
+				contents[name] = data
+			}
+		}
+		if first == nil {
+			first = contents
+			if len(first) == 0 {
+				t.Error("no type information files written")
+			}
+			continue
+		}
+		for name, data := range first {
+			if contents[name] != data {
+				t.Errorf("run %d wrote different type information to %s", run, name)
+			}
+		}
+	}
+}
+
+func TestESModule(t *testing.T) {
+	defer enter(t, "tests/esmodule")()
+	transpile(t, "esmodule.go", esModuleFlag)
+
+	code := readFile(t, "tardis/go-exports.js")
+	if !strings.Contains(code, "export {\n\tGo,\n") ||
+		!strings.Contains(code, "\tGo_main_EExported,\n") {
+		t.Error("exported function not in the ES module export list")
+	}
+	if strings.Contains(code, "Go_main_unexported") {
+		t.Error("unexported function in the ES module export list")
+	}
+}
+
+func TestDeterministicSchedule(t *testing.T) {
+	defer enter(t, "tests/schedule")()
+
+	transpile(t, "schedule.go")
+	if strings.Contains(readFile(t, "tardis/Go.hx"), "Scheduler.makeDeterministic(") {
+		t.Error("deterministic schedule set up by default")
+	}
+
+	*scheduleSeedFlag = 7
+	defer func() { *scheduleSeedFlag = 0 }()
+	transpile(t, "schedule.go")
+	if !strings.Contains(readFile(t, "tardis/Go.hx"), "Scheduler.makeDeterministic(7);") {
+		t.Error("deterministic schedule not set up")
+	}
+
+	var first string
+	for run := 0; run < 3; run++ {
+		out, err := haxeInterp().CombinedOutput()
+		if err != nil {
+			t.Error(err)
+		}
+		if strings.Count(string(out), "a") != 5 || strings.Count(string(out), "b") != 5 ||
+			!strings.Contains(string(out), " 10") {
+			t.Errorf("unexpected interleaving: %s", out)
+		}
+		if run == 0 {
+			first = string(out)
+		} else if string(out) != first {
+			t.Errorf("interleaving %s differs from the first run %s", out, first)
+		}
+	}
+}
+
+func TestBenchmarkHarness(t *testing.T) {
+	defer enter(t, "tests/benchmark")()
+
+	*benchFlag = "Fib"
+	defer func() { *benchFlag = "" }()
+	transpile(t, "github.com/tardisgo/tardisgo/tests/benchmark", testFlag)
+	if !strings.Contains(readFile(t, "tardis/Go_testing_MMain.hx"), `"Fib"`) {
+		t.Error("-bench pattern not given to testing.Main")
+	}
+
+	out, err := haxeInterp().CombinedOutput()
+	if err != nil {
+		t.Error(err)
+	}
+	if !regexp.MustCompile(`BenchmarkFib\t *[1-9][0-9]*\t *[0-9]+ ns/op`).Match(out) ||
+		strings.Contains(string(out), "BenchmarkNotMatched") {
+		t.Errorf("unexpected benchmark output: %s", out)
+	}
+}
+
+func BenchmarkAppendMillion(b *testing.B) {
+	defer enter(b, "tests/appendbench")()
+	transpile(b, "appendbench.go")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		out, err := haxeInterp().CombinedOutput()
+		if err != nil {
+			b.Fatal(err)
+		}
+		if !strings.Contains(string(out), "1000000 999999") {
+			b.Fatalf("appending a million ints gave: %s", out)
+		}
+	}
+}
+
+func TestMapElemNotAddressable(t *testing.T) {
+	compileFails(t, "tests/mapaddr", "mapaddr.go", `cannot take (the )?address`)
+}
+
+func TestNilPanicMessages(t *testing.T) {
+	for _, tst := range []struct{ name, want, notWant string }{
+		{"nilmap", "assignment to entry in nil map", "index out of range"},
+		{"nilslice", "index out of range", "assignment to entry in nil map"},
+		{"nilinterface", "Interface.invoke null Interface", "did not panic"},
+	} {
+		t.Run(tst.name, func(t *testing.T) {
+			defer enter(t, "tests/"+tst.name)()
+			transpile(t, tst.name+".go")
+			out, err := haxeInterp().CombinedOutput()
+			if err == nil {
+				t.Errorf("%s did not stop the program", tst.name)
+			}
+			if !strings.Contains(string(out), tst.want) || strings.Contains(string(out), tst.notWant) {
+				t.Errorf("%s gave: %s", tst.name, out)
+			}
+		})
+	}
+}
+
+func TestTypeInfoPruning(t *testing.T) {
+	defer enter(t, "tests/typeprune")()
+
+	transpile(t, "typeprune.go", fullTypesFlag)
+	full := readFile(t, "tardis/Tgotypes.hx")
+	fullIDs := readFile(t, "tardis/TypeInfo.hx")
+
+	transpile(t, "typeprune.go")
+	pruned := readFile(t, "tardis/Tgotypes.hx")
+	prunedIDs := readFile(t, "tardis/TypeInfo.hx")
+
+	if len(pruned) > len(full)*3/4 {
+		t.Errorf("type information not pruned for a program without reflect: %d bytes, %d in full", len(pruned), len(full))
+	}
+	if strings.Count(pruned, "public static function type") != strings.Count(full, "public static function type") ||
+		prunedIDs != fullIDs {
+		t.Error("pruning type information changed the type ids")
+	}
+
+	out, err := haxeInterp().CombinedOutput()
+	if err != nil {
+		t.Error(err)
+	}
+	if !strings.Contains(string(out), "9 3 true") {
+		t.Errorf("program with pruned type information gave: %s", out)
+	}
+}
+
+func TestStackAlloc(t *testing.T) {
+	defer enter(t, "tests/stackalloc")()
+	transpile(t, "stackalloc.go")
+
+	local := readFile(t, "tardis/Go_main_sumLLocal.hx")
+	if strings.Contains(local, "Object.make(8)") && !strings.Contains(local, "_stackalloc:Object=Object.make(8)") ||
+		!strings.Contains(local, "_stackalloc.clear()") {
+		t.Error("address of a local that does not escape allocated on the heap")
+	}
+	if strings.Contains(readFile(t, "tardis/Go_main_keepAAll.hx"), "_stackalloc") {
+		t.Error("address of a local that escapes allocated on the stack")
+	}
+
+	out, err := haxeInterp().CombinedOutput()
+	if err != nil {
+		t.Error(err)
+	}
+	if !strings.Contains(string(out), "2000000 0 1 2 500500") {
+		t.Errorf("unexpected output: %s", out)
+	}
+}
+
+func BenchmarkLocalAddr(b *testing.B) {
+	defer enter(b, "tests/allocbench")()
+
+	// count the Objects made by a million iterations that take the address of a local, with and without escape analysis
+	objects := func(noEscape bool) int {
+		*noEscapeFlag = noEscape
+		defer func() { *noEscapeFlag = false }()
+		transpile(b, "allocbench.go")
+		out, err := haxeInterp("-D", "countobjects").CombinedOutput()
+		if err != nil {
+			b.Fatal(err)
+		}
+		m := regexp.MustCompile(`2000000 objects ([0-9]+)`).FindSubmatch(out)
+		if m == nil {
+			b.Fatalf("taking the address of a local a million times gave: %s", out)
+		}
+		n, _ := strconv.Atoi(string(m[1]))
+		return n
+	}
+
+	for i := 0; i < b.N; i++ {
+		stack, heap := objects(false), objects(true)
+		if heap < 1000000 || stack >= heap/1000 {
+			b.Errorf("escape analysis made %d Objects, the heap %d", stack, heap)
+		}
+		if i == 0 {
+			b.Logf("escape analysis made %d Objects, allocating on the heap made %d", stack, heap)
+		}
+	}
+}
+
+func TestKeepDynamicMethods(t *testing.T) {
+	defer enter(t, "tests/keepmethod")()
+	transpile(t, "keepmethod.go")
+
+	if !strings.Contains(readFile(t, "tardis/Go_main_cln_main_dt_rect_AArea.hx"), "@:keep class Go_main_cln_main_dt_rect_AArea ") {
+		t.Error("method only called through an interface not kept")
+	}
+	if strings.Contains(readFile(t, "tardis/Go_main_perimeter.hx"), "@:keep") {
+		t.Error("statically called function kept from dead code elimination")
+	}
+
+	out, err := haxeInterp("-dce", "full").CombinedOutput()
+	if err != nil {
+		t.Error(err)
+	}
+	if !strings.Contains(string(out), "12 14") {
+		t.Errorf("unexpected output with full dead code elimination: %s", out)
+	}
+}
+
+func TestReaderInterfaces(t *testing.T) {
+	defer enter(t, "tests/reader")()
+	transpile(t, "reader.go")
+
+	if !strings.Contains(readFile(t, "tardis/Go_strings_cln__str_strings_dt_RReader_RRead.hx"), "@:keep class ") {
+		t.Error("strings.Reader Read method, only called via io.Reader, not kept")
+	}
+
+	out, err := haxeInterp().CombinedOutput()
+	if err != nil {
+		t.Error(err)
+	}
+	if !strings.Contains(string(out), "true 9 1 true") {
+		t.Errorf("unexpected output: %s", out)
+	}
+}
+
+func TestBinaryByteOrder(t *testing.T) {
+	defer enter(t, "tests/endian")()
+	transpile(t, "endian.go")
+
+	// check both Object layouts, the byte-addressed fullunsafe one is little-endian whatever the host
+	for _, defs := range [][]string{nil, {"-D", "fullunsafe"}} {
+		out, err := haxeInterp(defs...).CombinedOutput()
+		if err != nil {
+			t.Error(err)
+		}
+		if !strings.Contains(string(out), "endian failures: 0") {
+			t.Errorf("unexpected output with %v: %s", defs, out)
+		}
+	}
+}
+
+func TestManifest(t *testing.T) {
+	defer enter(t, "tests/manifest")()
+	transpile(t, "manifest.go", debugFlag, manifestFlag)
+
+	var manifest map[string]struct {
+		Func, Target, Position string
+		TypeIDs                []int
+	}
+	if err := json.Unmarshal([]byte(readFile(t, "tardis/manifest.json")), &manifest); err != nil {
+		t.Fatal(err)
+	}
+	compiled := 0
+	for _, code := range readTardis(t) {
+		if strings.Contains(code, " extends StackFrameBasis implements StackFrame {") {
+			compiled++
+		}
+	}
+	if compiled == 0 || len(manifest) != compiled {
+		t.Errorf("manifest has %d entries for %d compiled functions", len(manifest), compiled)
+	}
+	for key, fm := range manifest {
+		if _, err := os.Stat("tardis/Go_" + fm.Target + ".hx"); err != nil {
+			t.Errorf("manifest entry %s does not name a compiled function: %v", key, err)
+		}
+	}
+	inc, ok := manifest["*main.counter.inc"]
+	if !ok || inc.Func != "(*main.counter).inc" || !strings.HasSuffix(inc.Position, "manifest.go:7:19") || len(inc.TypeIDs) != 2 {
+		t.Errorf("unexpected manifest entry for a method: %+v", inc)
+	}
+	if _, ok := manifest["main.main$1"]; !ok {
+		t.Error("no manifest entry for a closure")
+	}
+	if strings.Contains(readFile(t, "tardis/Go_main_main.hx"), "implements StackFrame {  // ") {
+		t.Error("position written inline as well as in the manifest")
+	}
+}
+
+func TestChainedTypeAssert(t *testing.T) {
+	defer enter(t, "tests/chainassert")()
+	transpile(t, "chainassert.go")
+
+	out, err := haxeInterp().CombinedOutput()
+	if err == nil {
+		t.Error("failed type assertion did not stop the program")
+	}
+	if !strings.Contains(string(out), "valid chain b") ||
+		!strings.Contains(string(out), "interface type assert failed: cannot assert to main.renamer from main.widget") ||
+		strings.Contains(string(out), "did not panic") {
+		t.Errorf("chained type assertions gave: %s", out)
+	}
+}
+
+func TestOverflowTrapping(t *testing.T) {
+	defer enter(t, "tests/overflow")()
+
+	transpile(t, "overflow.go")
+	if strings.Contains(readFile(t, "tardis/Go_main_main.hx"), "Trap(") {
+		t.Error("overflow trapped by default")
+	}
+	out, err := haxeInterp().CombinedOutput()
+	if err != nil {
+		t.Error(err)
+	}
+	if !strings.Contains(string(out), "wrapped -128 -9223372036854775808 0") {
+		t.Errorf("overflow without trapping gave: %s", out)
+	}
+
+	transpile(t, "overflow.go", trapOverflowFlag)
+	code := readFile(t, "tardis/Go_main_main.hx")
+	if !strings.Contains(code, "Force.addTrap(") || !strings.Contains(code, "GOint64.mulTrap(") ||
+		strings.Count(code, "Trap(") != 2 {
+		t.Error("overflow checks not only on signed arithmetic")
+	}
+	out, err = haxeInterp().CombinedOutput()
+	if err == nil {
+		t.Error("overflow did not stop the program")
+	}
+	if !strings.Contains(string(out), "integer overflow: 127 + 1 overflows int8") || strings.Contains(string(out), "wrapped") {
+		t.Errorf("overflow with trapping gave: %s", out)
+	}
+}
+
+func TestConstEnums(t *testing.T) {
+	defer enter(t, "tests/enum")()
+	transpile(t, "enum.go")
+
+	enum := readFile(t, "tardis/GoType_main_dt_CColor.hx")
+	if !strings.Contains(enum, "@:enum abstract GoType_main_dt_CColor(Int) from Int to Int {\n"+
+		"var Red = 0;\nvar Green = 1;\nvar Blue = 2;\n}") {
+		t.Errorf("unexpected enum: %s", enum)
+	}
+	goClass := readFile(t, "tardis/Go.hx")
+	if !strings.Contains(goClass, "public static var main_AAnswer:Int = 42;") || strings.Contains(goClass, "main_RRed") {
+		t.Errorf("enum constants not separated from other constants: %s", goClass)
+	}
+	out, err := haxeInterp("tardis.GoType_main_dt_CColor").CombinedOutput()
+	if err != nil {
+		t.Error(err)
+	}
+	if !strings.Contains(string(out), "red blue 42") {
+		t.Errorf("enum program gave: %s", out)
+	}
+}
+
+func TestFuncCompareNotNil(t *testing.T) {
+	compileFails(t, "tests/funccompare", "funccompare.go", `can only be compared to nil`)
+}
+
+func TestParallelWorkers(t *testing.T) {
+	defer enter(t, "tests/typeids")() // a program of more than one package
+
+	transpile(t, "typeids.go")
+	serial := readTardis(t)
+	*workersFlag = 4
+	defer func() { *workersFlag = 0 }()
+	transpile(t, "typeids.go")
+	parallel := readTardis(t)
+
+	if len(serial) == 0 || len(serial) != len(parallel) {
+		t.Errorf("serial mode wrote %d files, parallel mode wrote %d", len(serial), len(parallel))
+	}
+	for name, data := range serial {
+		if parallel[name] != data {
+			t.Errorf("parallel mode output differs for %s", name)
+		}
+	}
+}
+
+func TestGrowSliceOverflow(t *testing.T) {
+	defer enter(t, "tests/growslice")()
+	transpile(t, "growslice.go")
+
+	out, err := haxeInterp().CombinedOutput()
+	if err == nil {
+		t.Error("append beyond the largest int did not stop the program")
+	}
+	if !strings.Contains(string(out), "growslice: cap out of range") || strings.Contains(string(out), "grew to") {
+		t.Errorf("append beyond the largest int gave: %s", out)
+	}
+}
+
+func TestNativeInt64(t *testing.T) {
+	defer enter(t, "tests/int64native")()
+
+	run := func(native bool) string {
+		*native64Flag = native
+		defer func() { *native64Flag = false }()
+		transpile(t, "int64native.go")
+		if strings.Contains(readFile(t, "tardis/Go_main_mix.hx"), ":haxe.Int64)*(") != native {
+			t.Errorf("native64=%v gave the wrong int64 multiply code", native)
+		}
+		conv := readFile(t, "tardis/Go_main_conv.hx")
+		if (strings.Contains(conv, "haxe.Int64.ofInt(") && strings.Contains(conv, ":haxe.Int64).low")) != native {
+			t.Errorf("native64=%v gave the wrong int64 conversion code", native)
+		}
+		out, err := haxeInterp().CombinedOutput()
+		if err != nil {
+			t.Error(err)
+		}
+		return string(out)
+	}
+
+	emulated := run(false)
+	native := run(true)
+	if !strings.Contains(emulated, "int64 ok") {
+		t.Errorf("emulated int64 gave: %s", emulated)
+	}
+	if native != emulated {
+		t.Errorf("native int64 gave: %s", native)
+	}
+
+	// haxe.Int64 is itself emulated by --interp, so the native code must also be run on a target with 64-bit integers
+	if err := exec.Command("haxelib", "path", "hxcpp").Run(); err != nil {
+		t.Skip("no hxcpp to build the cpp target:", err)
+	}
+	out, err := exec.Command("haxe", "-main", "tardis.Go", "-cp", "tardis", "-cpp", "tardis/cpp").CombinedOutput()
+	if err != nil {
+		t.Fatalf("building the cpp target: %v\n%s", err, out)
+	}
+	out, err = exec.Command("tardis/cpp/Go").CombinedOutput()
+	if err != nil {
+		t.Error(err)
+	}
+	if !strings.Contains(string(out), "int64 ok") {
+		t.Errorf("native int64 on the cpp target gave: %s", out)
+	}
+}
+
+func TestMapPointerMethod(t *testing.T) {
+	compileFails(t, "tests/mapmethod", "mapmethod.go", `cannot call pointer method`)
+}
+
+func TestStringIndexByte(t *testing.T) {
+	defer enter(t, "tests/stringindex")()
+	transpile(t, "stringindex.go")
+
+	out, err := haxeInterp().CombinedOutput()
+	if err == nil {
+		t.Error("indexing past the end of a string did not stop the program")
+	}
+	if !strings.Contains(string(out), "bytes 364") || // as given by the Go tool
+		!strings.Contains(string(out), "string index out of range") || strings.Contains(string(out), "did not panic") {
+		t.Errorf("string indexing gave: %s", out)
+	}
+}
+
+func TestTestHarness(t *testing.T) {
+	defer enter(t, "tests/testharness")()
+	transpile(t, "github.com/tardisgo/tardisgo/tests/testharness", testFlag)
+
+	out, err := haxeInterp().CombinedOutput()
+	if err == nil {
+		t.Error("a failing test did not give a non-zero exit code")
+	}
+	for _, want := range []string{
+		"--- PASS: TestAdd\n",
+		"--- PASS: TestAddSubtests/zero\n",
+		"--- PASS: TestAddSubtests/negative\n",
+		"--- PASS: TestAddSubtests\n",
+		"--- FAIL: TestBroken/fatal\n",
+		"--- PASS: TestBroken/after\n",
+		"--- FAIL: TestBroken\n",
+		"\nFAIL\n",
+	} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("test harness output does not contain %q: %s", want, out)
+		}
+	}
+	if strings.Contains(string(out), "carried on") {
+		t.Errorf("Fatalf did not end the test: %s", out)
+	}
+}
+
+func TestDivideByZero(t *testing.T) {
+	for _, name := range []string{"int", "int64"} {
+		out := transpileAndRun(t, "tests/divzero", name+".go") // the build tags only stop the go tool building both in one package
+		// the panic is a runtime.Error, which can be recovered, as given by the Go tool
+		want := "0 runtime error: integer divide by zero true\n" + name + " division by zero recovered\n"
+		if !strings.Contains(string(out), want) {
+			t.Errorf("%s division by zero gave: %s", name, out)
+		}
+	}
+}
+
+func TestInitOrder(t *testing.T) {
+	out := transpileAndRun(t, "tests/initorder", "initorder.go")
+	want := "430 43 42 21 [b.Seed b.Base b.init a.Derived a.init main.init]" // as given by the Go tool
+	if !strings.Contains(string(out), want) {
+		t.Errorf("initialization gave: %s", out)
+	}
+}
+
+func TestPrintBuiltins(t *testing.T) {
+	defer enter(t, "tests/println")()
+	transpile(t, "println.go")
+
+	cmd := haxeInterp()
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if err != nil {
+		t.Error(err)
+	}
+	want := "ints 42 -7 255 4294967295 -1099511627776 18446744073709551615\n" + // as given by the Go tool
+		"bool true false\n" +
+		"nil 0x0 [0/0]0x0\n" +
+		"nospaces1true\n"
+	if !strings.HasPrefix(stderr.String(), want) || !regexp.MustCompile(`\npointer 0x[0-9a-fA-F]+\n`).MatchString(stderr.String()) {
+		t.Errorf("print and println wrote to standard error: %s", stderr.String())
+	}
+	if strings.Contains(stdout.String(), "ints") {
+		t.Errorf("print and println wrote to standard output: %s", stdout.String())
+	}
+}
+
+func TestObjectArena(t *testing.T) {
+	defer enter(t, "tests/arena")()
+
+	run := func(arena bool) string {
+		*arenaFlag = arena
+		defer func() { *arenaFlag = false }()
+		transpile(t, "arena.go")
+		_, err := os.Stat("tardis/ObjectArena.hx")
+		if (err == nil) != arena {
+			t.Errorf("arena=%v gave the wrong ObjectArena class", arena)
+		}
+		if strings.Contains(readFile(t, "tardis/Object.hx"), "#error") != arena {
+			t.Errorf("arena=%v gave the wrong check of the Haxe defines", arena)
+		}
+		out, err := haxeInterp().CombinedOutput()
+		if err != nil {
+			t.Error(err)
+		}
+		return string(out)
+	}
+
+	objects := run(false)
+	arena := run(true)
+	if !strings.Contains(objects, "50 9600 0 {98 -51 2} s0 9.75") { // as given by the Go tool
+		t.Errorf("objects gave: %s", objects)
+	}
+	if arena != objects {
+		t.Errorf("the arena gave: %s", arena)
+	}
+}
+
+func TestNilDereference(t *testing.T) {
+	out := transpileAndRun(t, "tests/nilderef", "nilderef.go", nilCheckFlag)
+	msg := "runtime error: invalid memory address or nil pointer dereference"
+	want := "42 <nil> false\n1 " + msg + " true\n0 " + msg + " true\nstill running\n" // as given by the Go tool
+	if !strings.Contains(string(out), want) {
+		t.Errorf("nil dereference gave: %s", out)
+	}
+}
+
+func TestClearBuiltin(t *testing.T) {
+	out := transpileAndRun(t, "tests/clear", "clear.go")
+	want := "0 0 0 false\n1 4\n0\n" + // as given by the Go tool
+		`"a" 1 false|"" 0 true|"" 0 true|"d" 4 false|2 3` + "\n0\n"
+	if !strings.Contains(string(out), want) {
+		t.Errorf("clear gave: %s", out)
+	}
+}
+
+func TestLargeStructCopy(t *testing.T) {
+	*benchFlag = "LargeStructCopy"
+	defer func() { *benchFlag = "" }()
+	out := transpileAndRun(t, "tests/structcopy", "github.com/tardisgo/tardisgo/tests/structcopy", testFlag)
+	if !regexp.MustCompile(`BenchmarkLargeStructCopy\t *[1-9][0-9]*\t *[0-9]+ ns/op`).Match(out) ||
+		!strings.Contains(string(out), "--- PASS: TestTouch") {
+		t.Errorf("unexpected output from passing a large struct by value: %s", out)
+	}
+}
+
+func BenchmarkParallelWorkers(b *testing.B) {
+	defer enter(b, "tests/core")() // a large program, importing many packages
+
+	for _, workers := range []int{1, 4} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			*workersFlag = workers
+			defer func() { *workersFlag = 0 }()
+			for i := 0; i < b.N; i++ {
+				transpile(b, "test.go")
+			}
+		})
+	}
+}
+
 // NOTE: main Travis CI standard library tests are in a shell script in goroot/...
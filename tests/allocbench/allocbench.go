@@ -0,0 +1,24 @@
+// Counts the Objects made by a loop that takes the address of a local each iteration,
+// when the Haxe is compiled with -D countobjects.
+package main
+
+import "github.com/tardisgo/tardisgo/haxe/hx"
+
+type vec struct{ x, y int }
+
+func sumLocal(n int) int {
+	t := 0
+	for i := 0; i < n; i++ {
+		v := vec{i, 1}
+		p := &v
+		p.x++
+		t += p.x - i + p.y
+	}
+	return t
+}
+
+func main() {
+	before := hx.CodeInt("countobjects", "Object.made;")
+	t := sumLocal(1000000)
+	println(t, "objects", hx.CodeInt("countobjects", "Object.made;")-before)
+}
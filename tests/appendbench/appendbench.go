@@ -0,0 +1,16 @@
+package main
+
+// appends a million ints one at a time, which is only fast if append grows the capacity geometrically
+
+func main() {
+	var s []int
+	grown := 0
+	for i := 0; i < 1000000; i++ {
+		c := cap(s)
+		s = append(s, i)
+		if cap(s) != c {
+			grown++
+		}
+	}
+	println(len(s), s[len(s)-1], grown)
+}
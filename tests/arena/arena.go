@@ -0,0 +1,48 @@
+// A struct-heavy program, which must print the same whether or not objects are held in an arena.
+package main
+
+import "fmt"
+
+type point struct {
+	x, y int32
+	tag  string
+}
+
+type shape struct {
+	name   string
+	pts    [3]point
+	scale  float64
+	next   *shape
+	weight int64
+}
+
+func build(n int) *shape {
+	var head *shape
+	for i := 0; i < n; i++ {
+		s := shape{name: fmt.Sprintf("s%d", i), scale: float64(i) / 4, weight: int64(i) << 33}
+		for j := range s.pts {
+			s.pts[j] = point{int32(i * j), int32(-i - j), fmt.Sprint(j)}
+		}
+		s.next = head
+		head = &s
+	}
+	return head
+}
+
+func main() {
+	head := build(50)
+	shapes := []shape{}
+	for s := head; s != nil; s = s.next {
+		c := *s // a copy, which must not alias
+		c.pts[0].x = -1
+		shapes = append(shapes, c)
+	}
+	var sum int64
+	for _, s := range shapes {
+		for _, p := range s.pts {
+			sum += int64(p.x) + int64(p.y)
+		}
+		sum += s.weight >> 30
+	}
+	fmt.Println(len(shapes), sum, head.pts[0].x, shapes[0].pts[2], shapes[49].name, shapes[10].scale)
+}
@@ -0,0 +1,10 @@
+// Package benchmark is compiled with -test -bench, to run its benchmark on the target.
+package benchmark
+
+// Fib returns the nth Fibonacci number, slowly.
+func Fib(n int) int {
+	if n < 2 {
+		return n
+	}
+	return Fib(n-1) + Fib(n-2)
+}
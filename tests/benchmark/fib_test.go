@@ -0,0 +1,21 @@
+package benchmark
+
+import "testing"
+
+func TestFib(t *testing.T) {
+	if Fib(10) != 55 {
+		t.Error("Fib(10) != 55")
+	}
+}
+
+func BenchmarkFib(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		Fib(10)
+	}
+}
+
+func BenchmarkNotMatched(b *testing.B) { // not run when compiled with -bench Fib
+	for i := 0; i < b.N; i++ {
+		Fib(1)
+	}
+}
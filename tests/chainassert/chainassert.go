@@ -0,0 +1,26 @@
+package main
+
+// the first assertion of the chain succeeds, but the second must panic
+
+type namer interface {
+	Name() string
+}
+
+type renamer interface {
+	namer
+	Rename(string)
+}
+
+type widget struct{ name string }
+
+func (w widget) Name() string     { return w.name }
+func (w *widget) Rename(n string) { w.name = n }
+
+func main() {
+	var x interface{} = &widget{"a"}
+	x.(namer).(renamer).Rename("b")
+	println("valid chain", x.(namer).Name())
+	x = widget{"c"}
+	x.(namer).(renamer).Rename("d")
+	println("invalid chain did not panic")
+}
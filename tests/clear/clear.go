@@ -0,0 +1,37 @@
+// The clear builtin empties a map, or zeroes the elements of a slice.
+package main
+
+import "fmt"
+
+type item struct {
+	name string
+	n    int
+	p    *int
+}
+
+func main() {
+	m := map[string]int{"a": 1, "b": 2, "c": 3}
+	alias := m
+	clear(m)
+	v, ok := m["a"]
+	fmt.Println(len(m), len(alias), v, ok)
+	m["d"] = 4 // the map remains usable
+	fmt.Println(len(m), alias["d"])
+
+	var nm map[int]bool
+	clear(nm) // a no-op on a nil map
+	fmt.Println(len(nm))
+
+	x := 7
+	backing := []item{{"a", 1, &x}, {"b", 2, &x}, {"c", 3, &x}, {"d", 4, &x}}
+	s := backing[1:3]
+	clear(s)
+	for _, it := range backing {
+		fmt.Printf("%q %d %v|", it.name, it.n, it.p == nil)
+	}
+	fmt.Println(len(s), cap(s))
+
+	var ns []int
+	clear(ns) // a no-op on a nil slice
+	fmt.Println(len(ns))
+}
@@ -0,0 +1,11 @@
+package main
+
+const C = 1.5 + 2.25i
+const Re = real(C)
+const Im = imag(C)
+
+var v = 3 + 4i
+
+func main() {
+	println(Re, Im, real(C)*2, imag(v))
+}
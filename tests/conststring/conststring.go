@@ -0,0 +1,14 @@
+package main
+
+import "runtime"
+
+const Part = "middle"
+const Whole = "start " + Part + " end, " + runtime.GOOS
+
+var v = "middle"
+
+func main() {
+	folded := "start " + Part + " end" // all constant, so a single literal
+	mixed := "start " + v + " end"     // must stay a runtime concatenation
+	println(folded, mixed, Whole)
+}
@@ -10,6 +10,8 @@ package main
 import (
 	"errors"
 	"fmt"
+	"math"
+	"reflect"
 	"runtime"
 	"unicode"
 	"unicode/utf8"
@@ -199,6 +201,15 @@ const (
 	_, _                                 // skips iota == 2
 	bit3, mask3                          // bit3 == 8, mask3 == 7
 )
+const ( // two adjacent blocks of exported constants, each block restarts iota
+	Red = iota
+	Green
+	Blue
+)
+const (
+	Small = iota + 10
+	Large
+)
 const ren = '人'
 const Θ float64 = 3 / 2  // Θ == 1.0   (type float64, 3/2 is integer division)
 const Π float64 = 3 / 2. // Π == 1.5   (type float64, 3/2. is float division)
@@ -210,6 +221,16 @@ const k = 'w' + 1        // k == 'x'   (untyped rune constant)
 const l = "hi"           // l == "hi"  (untyped string constant)
 const m = string(k)      // m == "x"   (type string)
 
+// typed constants converted to other typed constants
+const i32neg int32 = -5
+const i64neg = int64(i32neg)
+const i32min int32 = -2147483648
+const i64min = int64(i32min)
+const u32max uint32 = 4294967295
+const u64max = uint64(u32max)
+const i64big int64 = 1 << 40
+const i32trunc = int32(i64big >> 20)
+
 func testConst() {
 	TEQ("", Name, "this is my name")
 	TEQ("", ests, true)
@@ -232,6 +253,9 @@ func testConst() {
 	TEQ("", Friday, 5)
 	TEQ("", Partyday, 6)
 	TEQ("", numberOfDays, 7) // this constant is not exported
+	TEQ("", Blue, 2)
+	TEQ("", Small, 10) // not 13, iota is reset for the new block
+	TEQ("", Large, 11)
 	TEQ("", c0, 0)           // c0 == 0
 	TEQ("", c1, 1)           // c1 == 1
 	TEQ("", c2, 2)           // c2 == 2
@@ -260,6 +284,11 @@ func testConst() {
 	TEQ("", k, 'x')  // k == 'x'   (untyped rune constant)
 	TEQ("", l, "hi") // l == "hi"  (untyped string constant)
 	TEQ("", m, "x")  // m == "x"   (type string)
+	TEQint64("", i64neg, -5)
+	TEQint64("", i64min, -2147483648)
+	TEQuint64("", u64max, 4294967295)
+	TEQint32("", i32trunc, 1<<20)
+	TEQint64("", i64neg*i64min, 10737418240) // must not overflow at the old width
 }
 
 var testUTFlength = "123456789"
@@ -351,6 +380,28 @@ func testStruct() {
 			TEQfloat("", PrivateStruct.h[i].y[j], PublicStruct.h[i].y[j], 1.0)
 		}
 	}
+
+	// comparing structs compares every element of their array fields
+	type withArray struct {
+		name string
+		vals [3]int
+	}
+	wa1 := withArray{"a", [3]int{1, 2, 3}}
+	wa2 := withArray{"a", [3]int{1, 2, 3}}
+	TEQ("", wa1 == wa2, true)
+	TEQ("", wa1 != wa2, false)
+	for i := range wa2.vals {
+		wa2.vals[i] = -1
+		TEQ("", wa1 == wa2, false)
+		TEQ("", wa1 != wa2, true)
+		wa2.vals[i] = wa1.vals[i]
+	}
+	TEQ("", wa1 == wa2, true)
+	var wai1, wai2 interface{} = wa1, wa2 // also when compared as interface values
+	TEQ("", wai1 == wai2, true)
+	wa2.vals[2] = 4
+	wai2 = wa2
+	TEQ("", wai1 == wai2, false)
 }
 func Sqrt(x float64) float64 {
 	z := x
@@ -387,10 +438,30 @@ func twoRets(x int) (a int, b string) {
 	return 42 * x, "forty-two"
 }
 
+var twoRetsCalls int
+
+func countedTwoRets(x int) (int, string) {
+	twoRetsCalls++
+	return twoRets(x)
+}
+
 func testMultiRet() {
 	r1, r2 := twoRets(1)
 	TEQ("", r1, 42)
 	TEQ("", r2, "forty-two")
+	var s struct {
+		a int
+		b string
+	}
+	s.a, s.b = countedTwoRets(2) // multiple return values straight into struct fields
+	TEQ("", s.a, 84)
+	TEQ("", s.b, "forty-two")
+	TEQ("", twoRetsCalls, 1)
+	ps := &s
+	ps.a, ps.b = countedTwoRets(3) // and via a pointer
+	TEQ("", s.a, 126)
+	TEQ("", s.b, "forty-two")
+	TEQ("", twoRetsCalls, 2)
 }
 
 func testAppend() {
@@ -411,6 +482,27 @@ func testAppend() {
 	var b []byte
 	b = append(b, "bar"...)
 	TEQbyteSlice("", b, []byte{'b', 'a', 'r'})
+
+	// nested appends, where the inner result may be reallocated before the outer append uses it
+	n := make([]int, 1, 2)
+	n = append(append(n, 1), 2) // inner fills the capacity, outer must reallocate
+	TEQintSlice("", n, []int{0, 1, 2})
+	n = append(append(append(n[:1:1], 3), 4, 5), n[1:]...)
+	TEQintSlice("", n, []int{0, 3, 4, 5, 1, 2})
+	shared := make([]int, 2, 10)
+	x := append(append(shared, 6), 7) // fits, so shares shared's array
+	y := append(append(shared, 8), 9)
+	TEQintSlice("", x, []int{0, 0, 8, 9}) // overwritten via y, as in Go
+	TEQintSlice("", y, []int{0, 0, 8, 9})
+	var ni []int
+	for i := 0; i < 10; i++ {
+		ni = append(append(ni, i), -i)
+	}
+	TEQ("", len(ni), 20)
+	for i := 0; i < 10; i++ {
+		TEQ("", ni[2*i], i)
+		TEQ("", ni[2*i+1], -i)
+	}
 }
 
 func testHeader() {
@@ -430,6 +522,35 @@ func testCopy() {
 	n3 := copy(b, "Hello, World!") // n3 == 5, b == []byte("Hello")
 	TEQ("", n3, 5)
 	TEQbyteSlice("", b, []byte("Hello"))
+	// overlapping copies must behave like memmove
+	s = []int{0, 1, 2, 3, 4, 5}
+	n4 := copy(s[1:], s) // n4 == 5, s == []int{0, 0, 1, 2, 3, 4}
+	TEQ("", n4, 5)
+	TEQintSlice("", s, []int{0, 0, 1, 2, 3, 4})
+	s = []int{0, 1, 2, 3, 4, 5}
+	n5 := copy(s, s[1:]) // n5 == 5, s == []int{1, 2, 3, 4, 5, 5}
+	TEQ("", n5, 5)
+	TEQintSlice("", s, []int{1, 2, 3, 4, 5, 5})
+	n6 := copy(a[2:], a[:]) // n6 == 6, slices of an array, rather than of a slice
+	TEQ("", n6, 6)
+	TEQintSlice("", a[:], []int{0, 1, 0, 1, 2, 3, 4, 5})
+	// copying from a string copies its UTF-8 bytes, and returns min(len(dst), len(src))
+	const src = "añ€b" // 1+2+3+1 bytes
+	for _, size := range []int{0, 1, 2, 3, 6, 7, 10} {
+		dst := make([]byte, size)
+		n := copy(dst, src)
+		want := size
+		if want > len(src) {
+			want = len(src)
+		}
+		TEQ("testCopy() string count", n, want)
+		TEQbyteSlice("testCopy() string bytes", dst[:n], []byte(src)[:want])
+	}
+	TEQ("testCopy() from empty string", copy(b, ""), 0)
+	TEQ("testCopy() string to nil slice", copy([]byte(nil), src), 0)
+	str := "xyz"
+	TEQ("testCopy() string to sub-slice", copy(b[3:], str), 2)
+	TEQbyteSlice("testCopy() string to sub-slice bytes", b, []byte("Helxy"))
 }
 
 func testInFuncPtr() { // there is no way to stop this use of pointers...
@@ -458,6 +579,52 @@ func testTweakFloatByReference(i *float64) {
 		*i = Sqrt(*i)
 	}
 }
+type bigValue struct {
+	a     [100]int
+	s     string
+	inner struct{ x, y float64 }
+}
+
+func (b bigValue) mutate() int {
+	b.a[99] = 1
+	b.inner.y = 2
+	return b.a[99]
+}
+
+func mutateBigValue(b bigValue, c chan int) int {
+	b.a[0]++
+	b.s = "changed"
+	b.inner.x = 3
+	if c != nil {
+		c <- b.a[0]
+	}
+	return b.a[0]
+}
+
+func testCallByBigValue() {
+	var b bigValue
+	b.a[0] = 10
+	b.s = "orig"
+	TEQ("", mutateBigValue(b, nil), 11)
+	TEQ("", b.a[0], 10)
+	TEQ("", b.s, "orig")
+	TEQfloat("", b.inner.x, 0, 0)
+
+	TEQ("", b.mutate(), 1)
+	TEQ("", b.a[99], 0)
+	TEQfloat("", b.inner.y, 0, 0)
+
+	c := make(chan int)
+	go mutateBigValue(b, c) // the argument is copied at the go statement
+	b.a[0] = 20
+	TEQ("", <-c, 11)
+	TEQ("", b.a[0], 20)
+
+	p := &b
+	TEQ("", mutateBigValue(*p, nil), 21)
+	TEQ("", p.a[0], 20)
+}
+
 func testCallBy() {
 	var a struct {
 		b int
@@ -485,6 +652,41 @@ func testCallBy() {
 	}
 }
 
+func testMapRangeDelete() {
+	m := make(map[int]bool)
+	for i := 0; i < 1000; i++ {
+		m[i] = true
+	}
+	seen := make(map[int]int)
+	first := true
+	for k := range m {
+		seen[k]++
+		if first { // delete every even key, including the current one and those not yet visited
+			first = false
+			for i := 0; i < 1000; i += 2 {
+				delete(m, i)
+			}
+		}
+		delete(m, k) // deleting the current key is always safe
+	}
+	TEQ("", len(m), 0)
+	evens := 0
+	for k, n := range seen {
+		if n != 1 {
+			TEQ("testMapRangeDelete key seen more than once", k, n)
+		}
+		if k%2 == 0 {
+			evens++
+		}
+	}
+	TEQ("", evens <= 1, true) // only the first key can be even
+	for i := 1; i < 1000; i += 2 {
+		if seen[i] != 1 {
+			TEQ("testMapRangeDelete odd key not visited", i, 1)
+		}
+	}
+}
+
 func testMap() { // and map-like constucts
 	// vowels[ch] is true if ch is a vowel
 	vowels := [128]bool{'a': true, 'e': true, 'i': true, 'o': true, 'u': true, 'y': true}
@@ -729,6 +931,62 @@ var three int = 3
 var uint64Global uint64
 var uint64GlobalArray [4]uint64
 
+var narrowingTests = []struct {
+	v   int64
+	i8  int8
+	u8  uint8
+	i16 int16
+	u16 uint16
+	i32 int32
+	u32 uint32
+}{
+	{0, 0, 0, 0, 0, 0, 0},
+	{127, 127, 127, 127, 127, 127, 127},
+	{128, -128, 128, 128, 128, 128, 128},
+	{255, -1, 255, 255, 255, 255, 255},
+	{256, 0, 0, 256, 256, 256, 256},
+	{-1, -1, 255, -1, 65535, -1, 4294967295},
+	{-128, -128, 128, -128, 65408, -128, 4294967168},
+	{-129, 127, 127, -129, 65407, -129, 4294967167},
+	{32767, -1, 255, 32767, 32767, 32767, 32767},
+	{32768, 0, 0, -32768, 32768, 32768, 32768},
+	{65535, -1, 255, -1, 65535, 65535, 65535},
+	{-32769, -1, 255, 32767, 32767, -32769, 4294934527},
+	{2147483647, -1, 255, -1, 65535, 2147483647, 2147483647},
+	{2147483648, 0, 0, 0, 0, -2147483648, 2147483648},
+	{4294967295, -1, 255, -1, 65535, -1, 4294967295},
+	{-2147483649, -1, 255, -1, 65535, 2147483647, 2147483647},
+}
+
+func testNarrowing() {
+	for _, nt := range narrowingTests {
+		// from a 64-bit value
+		TEQint32("int8(int64)", int32(int8(nt.v)), int32(nt.i8))
+		TEQuint32("uint8(int64)", uint32(uint8(nt.v)), uint32(nt.u8))
+		TEQint32("int16(int64)", int32(int16(nt.v)), int32(nt.i16))
+		TEQuint32("uint16(int64)", uint32(uint16(nt.v)), uint32(nt.u16))
+		TEQint32("int32(int64)", int32(nt.v), nt.i32)
+		TEQuint32("uint32(int64)", uint32(nt.v), nt.u32)
+		// from a 32-bit value
+		i := int(nt.i32)
+		TEQint32("int8(int)", int32(int8(i)), int32(nt.i8))
+		TEQuint32("uint8(int)", uint32(uint8(i)), uint32(nt.u8))
+		TEQint32("int16(int)", int32(int16(i)), int32(nt.i16))
+		TEQuint32("uint16(int)", uint32(uint16(i)), uint32(nt.u16))
+		TEQuint32("uint32(int)", uint32(i), nt.u32)
+		u := uint(nt.u32)
+		TEQint32("int8(uint)", int32(int8(u)), int32(nt.i8))
+		TEQint32("int16(uint)", int32(int16(u)), int32(nt.i16))
+		TEQint32("int32(uint)", int32(u), nt.i32)
+		// between the narrow types
+		TEQint32("int8(uint8)", int32(int8(nt.u8)), int32(nt.i8))
+		TEQuint32("uint8(int8)", uint32(uint8(nt.i8)), uint32(nt.u8))
+		TEQint32("int16(uint16)", int32(int16(nt.u16)), int32(nt.i16))
+		TEQuint32("uint16(int16)", uint32(uint16(nt.i16)), uint32(nt.u16))
+		TEQint32("int16(int8)", int32(int16(nt.i8)), int32(nt.i8))
+	}
+}
+
 func testIntOverflow() { //TODO add int64
 	TEQ(""+" int16 overflow test 1", int16_max+1, int16_mostNeg)
 	TEQ(""+" int8 overflow test 1", int8_max+1, int8_mostNeg)
@@ -923,6 +1181,42 @@ func testIntOverflow() { //TODO add int64
 	}
 }
 
+func testThreeIndexSlices() {
+	p := []int{2, 3, 5, 7, 11, 13}
+	q := p[1:3:4]
+	TEQ("", len(q), 2)
+	TEQ("", cap(q), 3)
+	TEQintSlice("", q, []int{3, 5})
+	q = append(q, 17) // within the restricted capacity, so shares p's array
+	TEQ("", p[3], 17)
+	q = append(q, 19) // beyond it, so must copy rather than overwrite p[4]
+	TEQ("", p[4], 11)
+	TEQintSlice("", q, []int{3, 5, 17, 19})
+	q[0] = 23
+	TEQ("", p[1], 3)
+
+	r := p[2:2:2]
+	TEQ("", len(r), 0)
+	TEQ("", cap(r), 0)
+	r = append(r, 29)
+	TEQ("", p[2], 5)
+	TEQ("", r[0], 29)
+
+	arr := [5]int{1, 2, 3, 4, 5}
+	s := arr[1:2:3]
+	TEQ("", len(s), 1)
+	TEQ("", cap(s), 2)
+	s = append(s, 6)
+	TEQ("", arr[2], 6)
+	s = append(s, 7)
+	TEQ("", arr[3], 4)
+	t := s[:cap(s):cap(s)] // re-slicing a copy
+	TEQ("", len(t), cap(s))
+
+	var n []int
+	TEQ("", n[0:0:0] == nil, true)
+}
+
 func testSlices() {
 	// from the Go tour...
 	p := []int{2, 3, 5, 7, 11, 13}
@@ -952,6 +1246,22 @@ func testSlices() {
 	TEQ("", cap(z), 0)
 	TEQ("", z == nil, true)
 
+	// the extremes of valid slice bounds
+	lo, hi := 0, 0
+	TEQ("", z[lo:hi] == nil, true)
+	lo, hi = 5, 5
+	TEQ("", len(d[:0][lo-2:hi-2]), 0) // high may exceed len, up to cap
+	TEQ("", len(c[lo:hi]), 0)
+	TEQ("", cap(c[lo:hi]), 0)
+	arr := [4]int{1, 2, 3, 4}
+	lo, hi = 1, 4
+	TEQintSlice("", arr[lo:hi], []int{2, 3, 4})
+	TEQ("", len(arr[hi:]), 0)
+	str := "hello"
+	lo, hi = 5, 5
+	TEQ("", str[lo:hi], "")
+	TEQ("", str[1:lo], "ello")
+	TEQ("", str[lo:], "")
 }
 
 func testUTF8() {
@@ -1030,6 +1340,31 @@ func testUTF8() {
 	//if ShowKnownErrors || hx.GetInt("", "'字'.length") == 3 {
 	TEQ(""+" NOTE: known error handling incorrect strings on UTF16 platforms", false, utf8.ValidString(invalid_string))
 	//}
+
+	testRangeString("a1", []int{0, 1}, []rune{'a', '1'})
+	testRangeString("é", []int{0}, []rune{'é'})
+	testRangeString("a界b", []int{0, 1, 4}, []rune{'a', '界', 'b'})
+	testRangeString("\U0001F600!", []int{0, 4}, []rune{0x1F600, '!'})
+	testRangeString("\xff", []int{0}, []rune{utf8.RuneError})
+	testRangeString("\xe4\xb8x", []int{0, 1, 2}, []rune{utf8.RuneError, utf8.RuneError, 'x'}) // truncated
+	testRangeString("\xc0\xaf", []int{0, 1}, []rune{utf8.RuneError, utf8.RuneError})          // overlong
+	testRangeString("\xed\xa0\x80", []int{0, 1, 2},
+		[]rune{utf8.RuneError, utf8.RuneError, utf8.RuneError}) // surrogate half
+	testRangeString("\xf4\x90\x80\x80", []int{0, 1, 2, 3},
+		[]rune{utf8.RuneError, utf8.RuneError, utf8.RuneError, utf8.RuneError}) // above U+10FFFF
+	testRangeString("", []int{}, []rune{})
+}
+
+func testRangeString(s string, idx []int, runes []rune) {
+	n := 0
+	for i, r := range s {
+		if n < len(idx) {
+			TEQ("testRangeString index "+s, i, idx[n])
+			TEQ("testRangeString rune "+s, r, runes[n])
+		}
+		n++
+	}
+	TEQ("testRangeString count "+s, n, len(idx))
 }
 
 func testChan() {
@@ -1054,6 +1389,19 @@ func testChan() {
 	}
 	TEQ("", rangeCount, 2)
 
+	// direction-restricted views share the underlying channel
+	bi := make(chan int, 1)
+	var sendOnly chan<- int = bi
+	var recvOnly <-chan int = bi
+	sendOnly <- 42
+	TEQ("", len(recvOnly), 1)
+	TEQ("", cap(sendOnly), 1)
+	TEQ("", <-recvOnly, 42)
+	TEQ("", recvOnly == bi, true)
+	TEQ("", sendOnly == bi, true)
+	go func(out chan<- int) { out <- 43 }(bi)
+	TEQ("", <-recvOnly, 43)
+
 	//TODO much more to come here...
 }
 
@@ -1066,6 +1414,14 @@ func testComplex() {
 	TEQfloat("", float64(real(x)), 1, 0.1)
 	TEQfloat("", float64(imag(x)), 2, 0.1)
 
+	const cc = -1.5 + 0.25i
+	const cr, ci = real(cc), imag(cc) // folded by the type checker, so usable as array lengths
+	var ca [int(-cr * 2)]byte
+	TEQ("", len(ca), 3)
+	TEQfloat("", cr, -1.5, 0)
+	TEQfloat("", ci, 0.25, 0)
+	TEQfloat("", float64(real(complex64(cc)*2)), -3, 0)
+
 	y = complex(3, 4)
 	TEQfloat("", float64(real(y)), 3, 0.1)
 	TEQfloat("", float64(imag(y)), 4, 0.1)
@@ -1119,6 +1475,28 @@ func testString() {
 	TEQ("", aString == aaString, false)
 	TEQ("", aString+aString == aaString, true)
 	TEQ("", bbString < aaString, false)
+
+	// Go orders strings byte-wise, where UTF-16 code-unit ordering would differ
+	replacement, smiley := "\uFFFD", "\U0001F600" // EF BF BD < F0 9F 98 80, but FFFD > D83D DE00
+	TEQ("", replacement < smiley, true)
+	TEQ("", replacement <= smiley, true)
+	TEQ("", replacement > smiley, false)
+	TEQ("", smiley >= replacement, true)
+	TEQ("", "\uFFFDa" < "\U0001F600", true)
+	TEQ("", "ab" < "ab\u00e9", true) // a prefix is always less
+	TEQ("", "\u00e9" > "z", true)    // C3 A9 > 7A
+	TEQ("", smiley <= smiley, true)
+
+	// string(byteSlice) must preserve every byte, even when it is not valid UTF-8
+	raw := []byte{0xff, 'a', 0xc0, 0x80, 0xe2, 0x82, 0, 0xf4, 0x90, 0x80, 0x80, 0x80}
+	rs := string(raw)
+	TEQ("", len(rs), len(raw))
+	for i := range raw {
+		TEQ("", rs[i], raw[i])
+	}
+	TEQbyteSlice("", []byte(rs), raw)
+	TEQ("", utf8.ValidString(rs), false)
+	TEQ("", rs == "\xffa\xc0\x80\xe2\x82\x00\xf4\x90\x80\x80\x80", true)
 }
 
 func adder() func(int) int {
@@ -1164,6 +1542,26 @@ func testClosure() {
 	TEQ("", f(), 2)
 	TEQ("", f(), 3)
 	TEQ("", f(), 5)
+
+	// closures nested within closures, capturing through both scopes
+	outer := nestedClosures(10)
+	middle := outer(20)
+	TEQ("", middle(), 30)
+	TEQ("", middle(), 31) // the outermost variable is shared, not copied
+	middle2 := outer(40)
+	TEQ("", middle2(), 52)
+	TEQ("", middle(), 33)
+}
+
+func nestedClosures(base int) func(int) func() int {
+	calls := 0
+	return func(mid int) func() int {
+		return func() int {
+			r := base + mid + calls
+			calls++
+			return r
+		}
+	}
 }
 
 func testVariadic(values ...int) {
@@ -1364,6 +1762,1111 @@ func testTour64() {
 	TEQ("", x+y, 12) // x & y could arrive in any order...
 }
 
+type goReceiver struct{ n int }
+
+func (g goReceiver) send(c chan int)     { c <- g.n }
+func (g *goReceiver) sendPtr(c chan int) { c <- g.n }
+
+type goSender interface {
+	send(c chan int)
+}
+
+func testGoMethodValue() {
+	c := make(chan int)
+
+	// the receiver is evaluated at the go statement, before the goroutine runs
+	g := goReceiver{1}
+	go g.send(c)
+	g.n = 2
+	TEQ("", <-c, 1)
+
+	gp := &goReceiver{3}
+	go gp.sendPtr(c)
+	gp = &goReceiver{4}
+	TEQ("", <-c, 3)
+
+	f := g.send // bound to a copy of g, which now has n==2
+	g.n = 5
+	go f(c)
+	g.n = 6
+	TEQ("", <-c, 2)
+
+	var i goSender = g
+	go i.send(c)
+	i = goReceiver{7}
+	TEQ("", <-c, 6)
+}
+
+type mixedRecv struct{ n int }
+
+func (m mixedRecv) Get() int   { return m.n }
+func (m *mixedRecv) Set(n int) { m.n = n }
+
+type getter interface {
+	Get() int
+}
+
+type setter interface {
+	Set(n int)
+}
+
+type getSetter interface {
+	getter
+	setter
+}
+
+func testMixedReceivers() {
+	var i interface{} = mixedRecv{1}
+	_, ok := i.(getter)
+	TEQ("", ok, true)
+	_, ok = i.(setter) // Set is only in the method set of *mixedRecv
+	TEQ("", ok, false)
+	_, ok = i.(getSetter)
+	TEQ("", ok, false)
+
+	p := &mixedRecv{2}
+	i = p
+	_, ok = i.(getter) // *mixedRecv also has the value methods of mixedRecv
+	TEQ("", ok, true)
+	gs, ok := i.(getSetter)
+	TEQ("", ok, true)
+	gs.Set(3)
+	TEQ("", gs.Get(), 3)
+	TEQ("", p.n, 3)
+
+	var g getter = mixedRecv{4}
+	var gp getter = &mixedRecv{4}
+	TEQ("", g == gp, false) // different dynamic types
+	TEQ("", g == getter(mixedRecv{4}), true)
+
+	st := reflect.TypeOf((*setter)(nil)).Elem()
+	TEQ("", reflect.TypeOf(mixedRecv{}).Implements(st), false)
+	TEQ("", reflect.TypeOf(&mixedRecv{}).Implements(st), true)
+	TEQ("", reflect.TypeOf(mixedRecv{}).NumMethod(), 1)
+	TEQ("", reflect.TypeOf(&mixedRecv{}).NumMethod(), 2)
+}
+
+type eqPoint struct {
+	x, y int
+	name string
+}
+
+type eqOtherPoint eqPoint
+
+func testInterfaceEquality() {
+	var a, b interface{} = eqPoint{1, 2, "p"}, eqPoint{1, 2, "p"}
+	TEQ("testInterfaceEquality() equal structs", a == b, true)
+	b = eqPoint{1, 3, "p"}
+	TEQ("testInterfaceEquality() differing structs", a == b, false)
+	b = eqPoint{1, 2, "q"}
+	TEQ("testInterfaceEquality() differing string fields", a == b, false)
+	b = eqOtherPoint{1, 2, "p"}
+	TEQ("testInterfaceEquality() same underlying type", a == b, false)
+	b = struct {
+		x, y int
+		name string
+	}{1, 2, "p"}
+	TEQ("testInterfaceEquality() unnamed struct type", a == b, false)
+	a, b = int32(7), int64(7)
+	TEQ("testInterfaceEquality() different int types", a == b, false)
+	a, b = 7, 7
+	TEQ("testInterfaceEquality() equal ints", a == b, true)
+	a, b = nil, eqPoint{}
+	TEQ("testInterfaceEquality() nil", a == b, false)
+}
+
+func nilTypeSwitch(i interface{}) string {
+	switch v := i.(type) {
+	case nil:
+		return "nil"
+	case *mixedRecv:
+		if v == nil {
+			return "typed nil"
+		}
+		return "pointer"
+	case getter:
+		return "getter"
+	default:
+		return "other"
+	}
+}
+
+func testTypeSwitchNil() {
+	TEQ("testTypeSwitchNil() nil interface", nilTypeSwitch(nil), "nil")
+	var p *mixedRecv
+	TEQ("testTypeSwitchNil() typed nil", nilTypeSwitch(p), "typed nil")
+	TEQ("testTypeSwitchNil() pointer", nilTypeSwitch(&mixedRecv{}), "pointer")
+	var g getter
+	TEQ("testTypeSwitchNil() nil getter", nilTypeSwitch(g), "nil")
+	g = p // *mixedRecv has the Get method of mixedRecv
+	TEQ("testTypeSwitchNil() typed nil getter", nilTypeSwitch(g), "typed nil")
+	var e error
+	TEQ("testTypeSwitchNil() nil error", nilTypeSwitch(e), "nil")
+	TEQ("testTypeSwitchNil() value", nilTypeSwitch(mixedRecv{}), "getter")
+}
+
+type celsius float64
+
+func (c celsius) String() string { return fmt.Sprintf("%.1fC", float64(c)) }
+
+type namedPoint struct{ x, y int }
+
+func (p *namedPoint) String() string { return fmt.Sprintf("(%d,%d)", p.x, p.y) }
+
+type codeErr int
+
+func (e codeErr) Error() string { return fmt.Sprintf("code %d", int(e)) }
+func (e codeErr) String() string { return "not used, as Error() takes priority" }
+
+type reading struct {
+	Temp  celsius
+	Where *namedPoint
+}
+
+func testStringerFormat() {
+	TEQ("testStringerFormat() %v", fmt.Sprintf("%v", celsius(21.5)), "21.5C")
+	TEQ("testStringerFormat() %s", fmt.Sprintf("%s", celsius(-3)), "-3.0C")
+	TEQ("testStringerFormat() %d", fmt.Sprintf("%.2f", celsius(1)), "1.00") // not a string verb
+	TEQ("testStringerFormat() pointer receiver", fmt.Sprintf("%v", &namedPoint{1, 2}), "(1,2)")
+	TEQ("testStringerFormat() value of pointer receiver", fmt.Sprintf("%v", namedPoint{1, 2}), "{1 2}")
+	TEQ("testStringerFormat() error", fmt.Sprintf("%v", codeErr(42)), "code 42")
+	var err error = codeErr(7)
+	TEQ("testStringerFormat() error interface", fmt.Sprint(err), "code 7")
+	TEQ("testStringerFormat() fields", fmt.Sprintf("%v", reading{20, &namedPoint{3, 4}}), "{20.0C (3,4)}")
+	TEQ("testStringerFormat() slice", fmt.Sprint([]celsius{1, 2}), "[1.0C 2.0C]")
+	TEQ("testStringerFormat() nil pointer", fmt.Sprintf("%v", (*namedPoint)(nil)), "<nil>")
+}
+
+type triple [3]int
+
+type pointPair [2]namedPoint
+
+func setFirst(t triple) triple {
+	t[0] = 9
+	return t
+}
+
+func testArrayConversion() {
+	a := [3]int{1, 2, 3}
+	t := triple(a)
+	t[0] = 99
+	TEQ("testArrayConversion() original unchanged", a, [3]int{1, 2, 3})
+	TEQ("testArrayConversion() converted", t, triple{99, 2, 3})
+	b := [3]int(t)
+	b[1] = 77
+	TEQ("testArrayConversion() converted back", t, triple{99, 2, 3})
+	TEQ("testArrayConversion() converted back copy", b, [3]int{99, 77, 3})
+	TEQ("testArrayConversion() parameter", setFirst(triple(a)), triple{9, 2, 3})
+	TEQ("testArrayConversion() parameter original", a, [3]int{1, 2, 3})
+
+	pp := [2]namedPoint{{1, 2}, {3, 4}}
+	np := pointPair(pp)
+	np[1].x = 30
+	TEQ("testArrayConversion() struct elements", pp[1].x, 3)
+	TEQ("testArrayConversion() struct elements converted", np[1].x, 30)
+}
+
+func nakedZero() (n int, err error, s string, f float64, p *int, a [2]int64) {
+	return
+}
+
+func nakedPartial(set bool) (n int, s string, b []byte) {
+	if set {
+		n = 42
+		b = append(b, 'x')
+	}
+	return
+}
+
+func nakedDeferred() (n int) {
+	defer func() { n *= 2 }()
+	n = 21
+	return
+}
+
+func mixedReturn() (a int, b string) {
+	b = "x"
+	return 5, b
+}
+
+func mixedReturnOverride() (a int, b string) {
+	a, b = 1, "named"
+	defer func() { b += "+deferred" }()
+	return a + 1, "explicit"
+}
+
+type counter struct{ n int }
+
+func (c counter) Value(scale int) int { return c.n * scale }
+func (c *counter) Add(d int) int     { c.n += d; return c.n }
+
+type valuer interface {
+	Value(int) int
+}
+
+type baseNamer struct{ name string }
+
+func (b baseNamer) Name() string      { return b.name }
+func (b *baseNamer) Rename(n string) { b.name = n }
+
+type namedWidget struct {
+	baseNamer
+	size int
+}
+
+type namedPtrWidget struct {
+	*baseNamer
+}
+
+type namer interface {
+	Name() string
+}
+
+type renamer interface {
+	namer
+	Rename(string)
+}
+
+type intHandler func(int) int
+
+func (h intHandler) twice(x int) int { return h(h(x)) }
+
+func addOne(x int) int { return x + 1 }
+
+func testNamedFuncConv() {
+	k := 10
+	closure := func(x int) int { return x * k }
+	h := intHandler(closure)
+	TEQ("testNamedFuncConv() closure to named", h(2), 20)
+	TEQ("testNamedFuncConv() method of named", h.twice(3), 300)
+	k = 2
+	TEQ("testNamedFuncConv() captured variable shared", h(5), 10)
+	back := (func(int) int)(h)
+	TEQ("testNamedFuncConv() named to unnamed", back(7), 14)
+	f := intHandler(addOne)
+	TEQ("testNamedFuncConv() function to named", f.twice(1), 3)
+	handlers := []intHandler{h, f, intHandler(back)}
+	sum := 0
+	for _, hd := range handlers {
+		sum += hd(1)
+	}
+	TEQ("testNamedFuncConv() in a slice", sum, 2+2+2)
+	var nilH intHandler
+	TEQ("testNamedFuncConv() nil named", (func(int) int)(nilH) == nil, true)
+}
+
+func testPromotedAssert() {
+	var n namer = namedWidget{baseNamer{"w"}, 3}
+	w, ok := n.(namedWidget)
+	TEQ("testPromotedAssert() to embedding type", ok, true)
+	TEQ("testPromotedAssert() promoted method", w.Name()+n.Name(), "ww")
+	TEQ("testPromotedAssert() own field", w.size, 3)
+	_, ok = n.(renamer) // Rename is only promoted to *namedWidget
+	TEQ("testPromotedAssert() value method set", ok, false)
+	_, ok = n.(baseNamer)
+	TEQ("testPromotedAssert() not the embedded type", ok, false)
+
+	n = &namedWidget{baseNamer{"p"}, 4}
+	r, ok := n.(renamer)
+	TEQ("testPromotedAssert() pointer method set", ok, true)
+	r.Rename("q")
+	pw := n.(*namedWidget)
+	TEQ("testPromotedAssert() pointer to embedding type", pw.Name()+pw.baseNamer.name, "qq")
+
+	n = namedPtrWidget{&baseNamer{"e"}}
+	r, ok = n.(renamer) // promoted through an embedded pointer, so in the value method set
+	TEQ("testPromotedAssert() embedded pointer", ok, true)
+	r.Rename("f")
+	TEQ("testPromotedAssert() embedded pointer type", n.(namedPtrWidget).Name(), "f")
+	switch v := n.(type) {
+	case namedWidget:
+		TEQ("testPromotedAssert() type switch wrong case", v.size, -1)
+	case namedPtrWidget:
+		TEQ("testPromotedAssert() type switch", v.name, "f")
+	}
+}
+
+func testChainedTypeAssert() {
+	var x interface{} = &namedWidget{baseNamer{"c"}, 5}
+	x.(namer).(renamer).Rename("d") // the intermediate value is a namer
+	TEQ("testChainedTypeAssert() interface chain", x.(namer).Name(), "d")
+	TEQ("testChainedTypeAssert() to concrete", x.(renamer).(*namedWidget).size, 5)
+	_, ok := x.(namer).(namedWidget)
+	TEQ("testChainedTypeAssert() second assertion comma-ok", ok, false)
+	x = namedWidget{baseNamer{"v"}, 6}
+	_, ok = x.(namer).(renamer)
+	TEQ("testChainedTypeAssert() value method set", ok, false)
+	TEQ("testChainedTypeAssert() value chain", x.(namer).(namedWidget).size, 6)
+}
+
+func testShiftWidth() {
+	for _, tst := range []struct {
+		by         uint
+		i32l, i32r int32
+		u32l, u32r uint32
+		i64l, i64r int64
+		u64l, u64r uint64
+	}{
+		{31, -1 << 31, -1, 1 << 31, 1, -1 << 31, -1 << 31, 1 << 31, 1<<33 - 1},
+		{32, 0, -1, 0, 0, -1 << 32, -1 << 30, 1 << 32, 1<<32 - 1},
+		{33, 0, -1, 0, 0, -1 << 33, -1 << 29, 1 << 33, 1<<31 - 1},
+		{63, 0, -1, 0, 0, -1 << 63, -1, 1 << 63, 1},
+		{64, 0, -1, 0, 0, 0, -1, 0, 0},
+		{65, 0, -1, 0, 0, 0, -1, 0, 0},
+		{1000, 0, -1, 0, 0, 0, -1, 0, 0},
+	} {
+		i32, u32 := int32(-1), uint32(1)
+		i64, u64 := int64(-1), uint64(1)
+		TEQ("testShiftWidth() int32 <<", i32<<tst.by, tst.i32l)
+		TEQ("testShiftWidth() int32 >> sign fill", (i32<<30)>>tst.by, tst.i32r)
+		TEQ("testShiftWidth() uint32 <<", u32<<tst.by, tst.u32l)
+		TEQ("testShiftWidth() uint32 >> zero fill", (u32<<31)>>tst.by, tst.u32r)
+		TEQ("testShiftWidth() int64 <<", i64<<tst.by, tst.i64l)
+		TEQ("testShiftWidth() int64 >> sign fill", (i64<<62)>>tst.by, tst.i64r)
+		TEQ("testShiftWidth() uint64 <<", u64<<tst.by, tst.u64l)
+		TEQ("testShiftWidth() uint64 >> zero fill", ^uint64(0)>>tst.by, tst.u64r)
+	}
+	var by64 uint64 = 1 << 32 // too big for 32 bits, so must not be truncated to a shift of 0
+	i, u := int32(-8), uint32(8)
+	I, U := int64(-8), uint64(8)
+	TEQ("testShiftWidth() int32 by uint64", i<<by64, int32(0))
+	TEQ("testShiftWidth() int32 >> by uint64", i>>by64, int32(-1))
+	TEQ("testShiftWidth() uint32 >> by uint64", u>>by64, uint32(0))
+	TEQ("testShiftWidth() int64 by uint64", I<<by64, int64(0))
+	TEQ("testShiftWidth() int64 >> by uint64", I>>by64, int64(-1))
+	TEQ("testShiftWidth() uint64 >> by uint64", U>>by64, uint64(0))
+	by64 = 3
+	TEQ("testShiftWidth() small uint64 amount", i<<by64+int32(I>>by64), int32(-65))
+	var b8 int8 = -128
+	var by8 uint8 = 8
+	TEQ("testShiftWidth() int8 >> width", b8>>by8, int8(-1))
+	TEQ("testShiftWidth() int8 << width", b8<<by8, int8(0))
+}
+
+type nestC struct {
+	pad int8
+	c   int32
+	arr [3]int16
+}
+
+type nestB struct {
+	name string
+	b    nestC
+}
+
+type nestA struct {
+	flag bool
+	a    nestB
+	x    float64
+}
+
+func testNestedFieldAddr() {
+	var s nestA
+	p := &s.a.b.c
+	*p = 42
+	TEQ("testNestedFieldAddr() outer struct sees the change", s.a.b.c, int32(42))
+	s.a.b.c++
+	TEQ("testNestedFieldAddr() pointer sees the outer change", *p, int32(43))
+	q := &s.a.b.arr[1]
+	*q = -7
+	TEQ("testNestedFieldAddr() array element in a nested struct", s.a.b.arr[1], int16(-7))
+	inner := &s.a.b
+	inner.pad = 1
+	TEQ("testNestedFieldAddr() intermediate pointer aliases", s.a.b.pad+int8(*p-42), int8(2))
+	TEQ("testNestedFieldAddr() neighbouring fields untouched", s.flag || s.x != 0 || s.a.name != "" || s.a.b.arr[0] != 0, false)
+	ps := []nestA{{}, {}}
+	r := &ps[1].a.b.c
+	*r = 9
+	TEQ("testNestedFieldAddr() via slice element", ps[1].a.b.c+ps[0].a.b.c, int32(9))
+	pa := &s
+	TEQ("testNestedFieldAddr() through a pointer to the outer struct", &pa.a.b.c == p, true)
+}
+
+func testIndexAddrAliases() {
+	s := []int{0, 1, 2, 3}
+	*(&s[2]) = 20 // a single use of the address
+	TEQ("testIndexAddrAliases() slice single use", s[2], 20)
+	p := &s[2]
+	*p += 5
+	TEQ("testIndexAddrAliases() slice", s[2], 25)
+	s[2] = 30
+	TEQ("testIndexAddrAliases() slice write seen through pointer", *p, 30)
+	t := s[1:3]
+	*(&t[1]) = 40
+	TEQ("testIndexAddrAliases() sub-slice shares the backing array", s[2], 40)
+	var arr [4]int16
+	q := &arr[3]
+	*q = -3
+	TEQ("testIndexAddrAliases() array", arr[3], int16(-3))
+	pa := &arr
+	*(&pa[0]) = 7
+	TEQ("testIndexAddrAliases() pointer to array", arr[0], int16(7))
+	vs := []struct{ x, y int }{{1, 2}, {3, 4}}
+	e := &vs[1]
+	e.y = 9
+	*e = struct{ x, y int }{e.x * 2, e.y}
+	TEQ("testIndexAddrAliases() struct element", vs[1].x+vs[1].y, 15)
+	TEQ("testIndexAddrAliases() other struct element untouched", vs[0].y, 2)
+}
+
+type collector interface {
+	Collect(prefix string, parts ...interface{}) int
+}
+
+type joinCollector struct{ got string }
+
+func (j *joinCollector) Collect(prefix string, parts ...interface{}) int {
+	j.got += prefix
+	for _, p := range parts {
+		j.got += fmt.Sprint(p)
+	}
+	return len(parts)
+}
+
+func testVariadicInvoke() {
+	var c collector = &joinCollector{}
+	TEQ("testVariadicInvoke() several args", c.Collect("a:", 1, "b", 2.5, true), 4)
+	TEQ("testVariadicInvoke() no variadic args", c.Collect("-"), 0)
+	args := []interface{}{"x", 'y'}
+	TEQ("testVariadicInvoke() slice passed with ...", c.Collect("/", args...), 2)
+	TEQ("testVariadicInvoke() all received", c.(*joinCollector).got, "a:1b2.5true-/x121")
+	var w interface {
+		Write(...byte) int
+	} = byteCounter(0)
+	TEQ("testVariadicInvoke() bytes", w.Write('a', 'b', 'c'), 3)
+}
+
+type byteCounter int
+
+func (b byteCounter) Write(p ...byte) int { return int(b) + len(p) }
+
+func testMapLookupZero() {
+	m := map[string]int{"a": 1}
+	v, ok := m["a"]
+	TEQ("testMapLookupZero() present comma-ok", v == 1 && ok, true)
+	v, ok = m["b"]
+	TEQ("testMapLookupZero() absent comma-ok", v == 0 && !ok, true)
+	TEQ("testMapLookupZero() absent", m["b"], 0)
+	var nm map[string]int
+	v, ok = nm["a"]
+	TEQ("testMapLookupZero() nil map comma-ok", v == 0 && !ok, true)
+	TEQ("testMapLookupZero() nil map", nm["a"], 0)
+	var ns map[int]struct{ x, y int }
+	st, ok := ns[1]
+	TEQ("testMapLookupZero() nil map struct comma-ok", st.x+st.y == 0 && !ok, true)
+	TEQ("testMapLookupZero() nil map struct", ns[2].y, 0)
+	var nc map[int]complex128
+	c, ok := nc[1]
+	TEQ("testMapLookupZero() nil map complex comma-ok", real(c)+imag(c) == 0 && !ok, true)
+	TEQ("testMapLookupZero() nil map complex", nc[2], complex128(0))
+	var nl map[int][]byte
+	sl, ok := nl[1]
+	TEQ("testMapLookupZero() nil map slice comma-ok", sl == nil && len(sl) == 0 && !ok, true)
+	var nmm map[int]map[int]int
+	inner, ok := nmm[1]
+	TEQ("testMapLookupZero() nil map map comma-ok", inner == nil && !ok, true)
+	TEQ("testMapLookupZero() nil map of nil map", nmm[1][2], 0)
+	var ni map[int]error
+	e, ok := ni[1]
+	TEQ("testMapLookupZero() nil map interface comma-ok", e == nil && !ok, true)
+	var n64 map[string]int64
+	TEQ("testMapLookupZero() nil map int64", n64["x"], int64(0))
+}
+
+func testNilInterface() {
+	var n namer
+	TEQ("testNilInterface() zero value is nil", n == nil, true)
+	var e interface{}
+	TEQ("testNilInterface() empty interface zero value is nil", e == nil, true)
+	n = namedWidget{}
+	TEQ("testNilInterface() not nil once set", n != nil, true)
+	n = nil
+	TEQ("testNilInterface() nil again", n == nil, true)
+	var p *namedWidget
+	n = p
+	TEQ("testNilInterface() holding a nil pointer is not nil", n != nil, true)
+	_, ok := e.(namer)
+	TEQ("testNilInterface() assertion on nil fails", ok, false)
+	var nn [2]namer
+	TEQ("testNilInterface() array element zero value", nn[1] == nil, true)
+}
+
+func testCappedAppend() {
+	a := []int{1, 2, 3}
+	b := a[0:1:1]
+	b = append(b, 9) // cap(b) is 1, so append must reallocate
+	TEQintSlice("testCappedAppend() parent unchanged", a, []int{1, 2, 3})
+	TEQintSlice("testCappedAppend() appended", b, []int{1, 9})
+	b[0] = 8
+	TEQ("testCappedAppend() no longer aliased", a[0], 1)
+
+	c := a[1:2:2]
+	c = append(c, a...) // append of a slice that may overlap the parent
+	TEQintSlice("testCappedAppend() parent unchanged after spread", a, []int{1, 2, 3})
+	TEQintSlice("testCappedAppend() spread", c, []int{2, 1, 2, 3})
+
+	arr := [4]byte{'a', 'b', 'c', 'd'}
+	d := arr[1:2:2]
+	d = append(d, "xy"...)
+	TEQ("testCappedAppend() array unchanged", string(arr[:]), "abcd")
+	TEQ("testCappedAppend() string appended", string(d), "bxy")
+
+	e := a[:2:3]
+	e = append(e, 7) // fits within the capacity, so still writes through
+	TEQ("testCappedAppend() within capacity aliases", a[2], 7)
+	e = append(e, 6)
+	TEQ("testCappedAppend() beyond capacity reallocates", len(a), 3)
+	e[0] = 5
+	TEQ("testCappedAppend() reallocated", a[0], 1)
+}
+
+var nilFuncGlobal func() int
+
+func testFuncNil() {
+	var f func(int) int
+	TEQ("testFuncNil() zero value", f == nil, true)
+	TEQ("testFuncNil() nil on the left", nil != f, false)
+	TEQ("testFuncNil() global zero value", nilFuncGlobal == nil, true)
+	k := 3
+	f = func(i int) int { return i + k }
+	TEQ("testFuncNil() closure", f != nil, true)
+	f = testFuncNilDouble
+	TEQ("testFuncNil() top-level function", f == nil, false)
+	f = nil
+	TEQ("testFuncNil() reset", f == nil, true)
+	var s struct{ cb func() }
+	TEQ("testFuncNil() struct field", s.cb == nil, true)
+	fs := make([]func(), 2)
+	fs[1] = func() {}
+	TEQ("testFuncNil() slice elements", fs[0] == nil && fs[1] != nil, true)
+	m := map[string]func(){}
+	TEQ("testFuncNil() missing map entry", m["x"] == nil, true)
+	var iface interface{} = f
+	TEQ("testFuncNil() nil func in an interface", iface != nil, true)
+}
+
+func testFuncNilDouble(i int) int { return i * 2 }
+
+type embReader interface {
+	Read(p []byte) (int, error)
+}
+
+type embReadWriter interface {
+	embReader
+	Write(p []byte) (int, error)
+}
+
+type embBuffer struct{ data []byte }
+
+func (b *embBuffer) Read(p []byte) (int, error) {
+	n := copy(p, b.data)
+	b.data = b.data[n:]
+	return n, nil
+}
+
+func (b *embBuffer) Write(p []byte) (int, error) {
+	b.data = append(b.data, p...)
+	return len(p), nil
+}
+
+type embPromoted struct { // Read comes from the embedded struct, Write from the embedded interface
+	*embBuffer
+	io embReadWriter
+}
+
+func (e embPromoted) Write(p []byte) (int, error) { return e.io.Write(p) }
+
+type embIfaceField struct {
+	embReadWriter // both methods promoted from an embedded interface field
+}
+
+func testEmbeddedInterfaceMethods() {
+	var rw embReadWriter = &embBuffer{}
+	rw.Write([]byte("abc"))
+	p := make([]byte, 2)
+	n, _ := rw.Read(p) // promoted from the embedded interface
+	TEQ("testEmbeddedInterfaceMethods() Read via ReadWriter", string(p[:n]), "ab")
+	var r embReader = rw
+	n, _ = r.Read(p)
+	TEQ("testEmbeddedInterfaceMethods() Read via Reader", string(p[:n]), "c")
+
+	buf := &embBuffer{}
+	rw = embPromoted{buf, buf}
+	rw.Write([]byte("xyz"))
+	n, _ = rw.Read(p)
+	TEQ("testEmbeddedInterfaceMethods() Read promoted from a struct", string(p[:n]), "xy")
+
+	rw = embIfaceField{&embBuffer{data: []byte("q")}}
+	n, _ = rw.Read(p)
+	TEQ("testEmbeddedInterfaceMethods() Read promoted from an interface field", string(p[:n]), "q")
+	var x interface{} = rw
+	_, ok := x.(embReader)
+	TEQ("testEmbeddedInterfaceMethods() assert to the embedded interface", ok, true)
+}
+
+type taggedEscapes struct {
+	Quoted    int "json:\"a\\\"b\""
+	Backslash int `path:"c:\\dir"`
+	Tabbed    int "x:\"1\"\ty:\"2\""
+	Multiline int `first:"1"
+second:"2"`
+	Unicode int `name:"été"`
+}
+
+func testStructTagEscapes() {
+	typ := reflect.TypeOf(taggedEscapes{})
+	TEQ("testStructTagEscapes() quote", string(typ.Field(0).Tag), `json:"a\"b"`)
+	TEQ("testStructTagEscapes() quote Get", typ.Field(0).Tag.Get("json"), `a"b`)
+	TEQ("testStructTagEscapes() backslash Get", typ.Field(1).Tag.Get("path"), `c:\dir`)
+	TEQ("testStructTagEscapes() tab", string(typ.Field(2).Tag), "x:\"1\"\ty:\"2\"")
+	TEQ("testStructTagEscapes() tab Get", typ.Field(2).Tag.Get("y"), "")
+	TEQ("testStructTagEscapes() newline", string(typ.Field(3).Tag), "first:\"1\"\nsecond:\"2\"")
+	TEQ("testStructTagEscapes() unicode Get", typ.Field(4).Tag.Get("name"), "été")
+}
+
+type ifaceFields struct {
+	n    int
+	err  error
+	p    *int
+	vals [2]interface{}
+	fn   func()
+}
+
+func testIfaceFieldZero() {
+	var v ifaceFields
+	TEQ("testIfaceFieldZero() var error field", v.err == nil, true)
+	TEQ("testIfaceFieldZero() var pointer field", v.p == nil, true)
+	TEQ("testIfaceFieldZero() var interface array", v.vals[0] == nil && v.vals[1] == nil, true)
+	h := new(ifaceFields) // heap allocated
+	TEQ("testIfaceFieldZero() new error field", h.err == nil, true)
+	_, isErr := h.vals[1].(error)
+	TEQ("testIfaceFieldZero() assert on a zero field", isErr, false)
+	lit := &ifaceFields{n: 1}
+	TEQ("testIfaceFieldZero() literal error field", lit.err == nil && lit.fn == nil, true)
+	var e interface{} = lit.err
+	TEQ("testIfaceFieldZero() copied out to an interface", e == nil, true)
+	s := make([]ifaceFields, 2)
+	TEQ("testIfaceFieldZero() slice element", s[1].err == nil && s[1].p == nil, true)
+	h.err = errSentinel
+	*h = ifaceFields{} // re-zeroed
+	TEQ("testIfaceFieldZero() re-zeroed", h.err == nil, true)
+	for i := 0; i < 2; i++ {
+		var l ifaceFields // a new zero value on each iteration, even if its memory is reused
+		TEQ("testIfaceFieldZero() loop local", l.err == nil && l.vals[0] == nil, true)
+		l.err = errSentinel
+		l.vals[0] = i
+		ip := &l.err
+		TEQ("testIfaceFieldZero() loop local set", *ip != nil, true)
+	}
+}
+
+func testTypedNils() {
+	c := make(chan int, 1)
+	m := map[string]int{"a": 1}
+	s := []int{1}
+	c, m, s = nil, nil, nil
+	TEQ("testTypedNils() chan", c == nil, true)
+	TEQ("testTypedNils() map", m == nil, true)
+	TEQ("testTypedNils() slice", s == nil, true)
+	TEQ("testTypedNils() chan len & cap", len(c)+cap(c), 0)
+	select {
+	case <-c:
+		TEQ("testTypedNils() nil chan is never ready", true, false)
+	default:
+	}
+	TEQ("testTypedNils() map len", len(m), 0)
+	v, ok := m["a"]
+	TEQ("testTypedNils() map lookup", v == 0 && !ok, true)
+	delete(m, "a") // no-op on a nil map
+	nilMaps := []map[string]int{nil, {"b": 2}}
+	for _, nm := range nilMaps {
+		delete(nm, "b")
+		TEQ("testTypedNils() delete from a map variable", len(nm), 0)
+	}
+	for range m {
+		TEQ("testTypedNils() nil map range", true, false)
+	}
+	TEQ("testTypedNils() slice len & cap", len(s)+cap(s), 0)
+	s = append(s, 2)
+	TEQintSlice("testTypedNils() append to nil slice", s, []int{2})
+
+	var ic interface{} = (chan int)(nil)
+	_, isChan := ic.(chan int)
+	TEQ("testTypedNils() typed nil chan in an interface", ic != nil && isChan, true)
+	var im interface{} = map[string]int(nil)
+	TEQ("testTypedNils() typed nil map in an interface", im.(map[string]int) == nil, true)
+	var is interface{} = []int(nil)
+	TEQ("testTypedNils() typed nil slice in an interface", is.([]int) == nil, true)
+}
+
+func variadicFirstToZero(vals ...int) (isNil bool, n int) {
+	if len(vals) > 0 {
+		vals[0] = 0
+	}
+	return vals == nil, len(vals)
+}
+
+func variadicAfter(prefix string, vals ...string) []string { return vals }
+
+func testVariadicSpread() {
+	s := []int{1, 2, 3}
+	_, n := variadicFirstToZero(s...) // the slice itself is passed, not a copy
+	TEQ("testVariadicSpread() spread length", n, 3)
+	TEQ("testVariadicSpread() spread aliases the caller's slice", s[0], 0)
+	a, b := 4, 5
+	_, n = variadicFirstToZero(a, b) // packed into a fresh slice
+	TEQ("testVariadicSpread() packed length", n, 2)
+	TEQ("testVariadicSpread() packed does not alias the arguments", a, 4)
+	isNil, n := variadicFirstToZero()
+	TEQ("testVariadicSpread() empty call passes nil", isNil && n == 0, true)
+	isNil, _ = variadicFirstToZero([]int{}...)
+	TEQ("testVariadicSpread() empty spread is not nil", isNil, false)
+	isNil, _ = variadicFirstToZero(nil...)
+	TEQ("testVariadicSpread() nil spread", isNil, true)
+
+	strs := []string{"x", "y"}
+	got := variadicAfter("p", strs...)
+	TEQ("testVariadicSpread() spread is the same slice", &got[0] == &strs[0], true)
+	got = variadicAfter("p", strs[1:]...)
+	TEQ("testVariadicSpread() spread of a sub-slice", &got[0] == &strs[1], true)
+	TEQ("testVariadicSpread() packed is a new slice", &variadicAfter("p", "x")[0] != &strs[0], true)
+}
+
+type mapMethodVal struct {
+	n    int
+	arr  [2]int
+	name string
+}
+
+// bump changes only its copy of the receiver
+func (v mapMethodVal) bump() int {
+	v.n++
+	v.arr[0] = v.n
+	return v.n + v.arr[0]
+}
+
+func (v mapMethodVal) label() string { return v.name }
+
+func testMapValueMethod() {
+	m := map[string]mapMethodVal{"a": {n: 1, name: "first"}}
+	TEQ("testMapValueMethod() value receiver on m[k]", m["a"].bump(), 4)
+	TEQ("testMapValueMethod() the map value is unchanged", m["a"].n, 1)
+	TEQ("testMapValueMethod() array field unchanged", m["a"].arr[0], 0)
+	TEQ("testMapValueMethod() missing key uses the zero value", m["b"].bump(), 2)
+	TEQ("testMapValueMethod() missing key is not added", len(m), 1)
+	f := m["a"].label // the receiver is copied when the method value is made
+	m["a"] = mapMethodVal{name: "second"}
+	TEQ("testMapValueMethod() method value keeps its copy", f(), "first")
+	TEQ("testMapValueMethod() map holds the new value", m["a"].label(), "second")
+}
+
+type deferArgLog struct{ out []string }
+
+func (l *deferArgLog) printf(format string, args ...interface{}) {
+	l.out = append(l.out, fmt.Sprintf(format, args...))
+}
+
+type deferArgPair struct{ a, b int }
+
+func deferArgLoop(log *deferArgLog, xs []chan int) {
+	for i := range xs {
+		defer close(xs[i])
+	}
+	for i := 0; i < 3; i++ {
+		defer log.printf("i=%d", i) // i is evaluated now, not when log.printf runs
+	}
+	p := deferArgPair{1, 2}
+	defer log.printf("p=%v", p) // so is the copy of the struct
+	p.a = 99
+	var s fmt.Stringer = deferArgStringer("before")
+	defer log.printf("s=%s", s.String())
+	s = deferArgStringer("after")
+	f := log.printf
+	defer f("f")
+	f = nil
+}
+
+type deferArgStringer string
+
+func (d deferArgStringer) String() string { return string(d) }
+
+func testDeferArgs() {
+	log := &deferArgLog{}
+	xs := []chan int{make(chan int), make(chan int), make(chan int)}
+	deferArgLoop(log, xs)
+	TEQ("testDeferArgs() values and order", fmt.Sprint(log.out), "[f s=before p={1 2} i=2 i=1 i=0]")
+	for i, x := range xs {
+		_, ok := <-x
+		TEQ(fmt.Sprintf("testDeferArgs() xs[%d] closed", i), ok, false)
+	}
+}
+
+func testStringIndexByte() {
+	s := "héllo, 世界"
+	TEQ("testStringIndexByte() bytes of é", int(s[1])+int(s[2]), 0xc3+0xa9)
+	var b byte = s[1]
+	b++ // byte arithmetic wraps at 8 bits
+	TEQ("testStringIndexByte() byte arithmetic", b, byte(0xc4))
+	TEQ("testStringIndexByte() ascii", s[0]-'a', byte(7))
+	sum := 0
+	for i := 0; i < len(s); i++ {
+		sum += int(s[i])
+	}
+	TEQ("testStringIndexByte() sum of all bytes", sum, 1953)
+	TEQ("testStringIndexByte() last byte", s[len(s)-1], byte(0x8c))
+}
+
+func testSignedDivMod() {
+	// Go division truncates toward zero, and the remainder takes the sign of the dividend
+	for _, tst := range []struct{ x, y, q, r int64 }{
+		{7, 3, 2, 1}, {-7, 3, -2, -1}, {7, -3, -2, 1}, {-7, -3, 2, -1},
+		{6, 3, 2, 0}, {-6, 3, -2, 0}, {6, -3, -2, 0}, {-6, -3, 2, 0},
+		{2, 5, 0, 2}, {-2, 5, 0, -2}, {2, -5, 0, 2}, {-2, -5, 0, -2},
+		{0, 3, 0, 0}, {0, -3, 0, 0}, {-100, 7, -14, -2}, {100, -7, -14, 2},
+	} {
+		name := fmt.Sprintf("testSignedDivMod() %d,%d", tst.x, tst.y)
+		TEQ(name+" int /", int(tst.x)/int(tst.y), int(tst.q))
+		TEQ(name+" int %", int(tst.x)%int(tst.y), int(tst.r))
+		TEQ(name+" int8 /", int8(tst.x)/int8(tst.y), int8(tst.q))
+		TEQ(name+" int8 %", int8(tst.x)%int8(tst.y), int8(tst.r))
+		TEQ(name+" int16 /", int16(tst.x)/int16(tst.y), int16(tst.q))
+		TEQ(name+" int16 %", int16(tst.x)%int16(tst.y), int16(tst.r))
+		TEQ(name+" int32 /", int32(tst.x)/int32(tst.y), int32(tst.q))
+		TEQ(name+" int32 %", int32(tst.x)%int32(tst.y), int32(tst.r))
+		TEQ(name+" int64 /", tst.x/tst.y, tst.q)
+		TEQ(name+" int64 %", tst.x%tst.y, tst.r)
+		big := tst.x << 33 // the same signs, with values that need all 64 bits
+		TEQ(name+" big int64 /", big/(tst.y<<33), tst.q)
+		TEQ(name+" big int64 %", big%(tst.y<<33), tst.r<<33)
+	}
+	minusOne := int64(-1)
+	min64 := int64(-1 << 63)
+	TEQ("testSignedDivMod() min int64 / -1", min64/minusOne, min64)
+	TEQ("testSignedDivMod() min int64 % -1", min64%minusOne, int64(0))
+	min32 := int32(-1 << 31)
+	TEQ("testSignedDivMod() min int32 / -1", min32/int32(minusOne), min32)
+	TEQ("testSignedDivMod() min int32 % -1", min32%int32(minusOne), int32(0))
+}
+
+func testSubsliceAliasing() {
+	a := []int{0, 1, 2, 3, 4, 5}
+	b := a[2:4]
+	b[0] = 99
+	TEQ("testSubsliceAliasing() write through a subslice", a[2], 99)
+	a[3] = 42
+	TEQ("testSubsliceAliasing() write through the parent", b[1], 42)
+	c := b[1:3] // beyond the length of b, but within its capacity
+	c[1] = 77
+	TEQ("testSubsliceAliasing() subslice of a subslice", a[4], 77)
+
+	z := a[2:2] // zero length, but sharing the backing array
+	z = append(z, -1)
+	TEQ("testSubsliceAliasing() append to a zero-length reslice", a[2], -1)
+	TEQ("testSubsliceAliasing() append to a zero-length reslice result", z[0], -1)
+
+	full := a[len(a):cap(a)] // at the capacity, so append must copy
+	full = append(full, 123)
+	full[0] = 456
+	TEQ("testSubsliceAliasing() append at cap does not alias", a[5], 5)
+	TEQ("testSubsliceAliasing() append at cap length", len(full), 1)
+
+	limited := a[1:2:2]
+	limited = append(limited, 8) // the three-index cap forces a copy
+	TEQ("testSubsliceAliasing() three-index reslice protects the parent", a[2], -1)
+	limited[0] = 9
+	TEQ("testSubsliceAliasing() copied slice does not alias", a[1], 1)
+
+	arr := [4]string{"w", "x", "y", "z"}
+	s := arr[1:3]
+	s[1] = "Y"
+	TEQ("testSubsliceAliasing() slice of an array", arr[2], "Y")
+	TEQ("testSubsliceAliasing() slice of an array cap", cap(s), 3)
+}
+
+func testMethodExpr() {
+	a, b := counter{2}, counter{5}
+	table := []func(counter, int) int{counter.Value, (counter).Value}
+	TEQ("testMethodExpr() value receiver a", table[0](a, 10), 20)
+	TEQ("testMethodExpr() value receiver b", table[1](b, 10), 50)
+	byPtr := (*counter).Value // a wrapper that dereferences its first argument
+	TEQ("testMethodExpr() value method via pointer", byPtr(&b, 3), 15)
+	add := (*counter).Add
+	TEQ("testMethodExpr() pointer receiver", add(&a, 1), 3)
+	TEQ("testMethodExpr() pointer receiver updated", a.n, 3)
+	ops := map[string]func(*counter, int) int{"add": (*counter).Add, "value": (*counter).Value}
+	ops["add"](&b, 2)
+	TEQ("testMethodExpr() dispatch table", ops["value"](&b, 2), 14)
+	iv := valuer.Value // an interface method expression
+	TEQ("testMethodExpr() interface", iv(a, 7)+iv(&b, 1), 28)
+}
+
+func testMixedReturn() {
+	a, b := mixedReturn()
+	TEQ("testMixedReturn() explicit", a, 5)
+	TEQ("testMixedReturn() named", b, "x")
+	a, b = mixedReturnOverride()
+	TEQ("testMixedReturn() override explicit", a, 2)
+	TEQ("testMixedReturn() override deferred", b, "explicit+deferred")
+}
+
+func testNakedReturn() {
+	n, err, s, f, p, a := nakedZero()
+	TEQ("testNakedReturn() zero int", n, 0)
+	TEQ("testNakedReturn() zero error", err == nil, true)
+	TEQ("testNakedReturn() zero string", s, "")
+	TEQfloat("testNakedReturn() zero float", f, 0, 0)
+	TEQ("testNakedReturn() zero pointer", p == nil, true)
+	TEQ("testNakedReturn() zero array", a, [2]int64{})
+	n, s, b := nakedPartial(true)
+	TEQ("testNakedReturn() partial int", n, 42)
+	TEQ("testNakedReturn() partial string", s, "")
+	TEQ("testNakedReturn() partial slice", string(b), "x")
+	n, s, b = nakedPartial(false)
+	TEQ("testNakedReturn() unset int", n, 0)
+	TEQ("testNakedReturn() unset slice", b == nil, true)
+	TEQ("testNakedReturn() deferred", nakedDeferred(), 42)
+}
+
+func testCompositeLitAddr() {
+	p := &[]int{1, 2, 3}[1] // slice literal elements are addressable, unlike those of map literals
+	*p = 20
+	TEQ("testCompositeLitAddr() slice element", *p, 20)
+	q := &[]namedPoint{{1, 2}, {3, 4}}[1]
+	q.y = 40
+	TEQ("testCompositeLitAddr() slice struct element", *q, namedPoint{3, 40})
+	s := []int{1, 2, 3}
+	r := &s[2]
+	*r = 30
+	TEQ("testCompositeLitAddr() slice variable element", s[2], 30)
+	a := &[...]int{1, 2, 3} // arrays only through a pointer to the literal
+	b := &a[0]
+	*b = 10
+	TEQ("testCompositeLitAddr() array element", a[0], 10)
+}
+
+func goexitWorker(c chan string) {
+	defer func() {
+		c <- fmt.Sprint("deferred ", recover()) // Goexit is not a panic, so there is nothing to recover
+	}()
+	c <- "started"
+	runtime.Goexit()
+	c <- "not reached"
+}
+
+func testGoexit() {
+	c := make(chan string)
+	other := make(chan int)
+	go goexitWorker(c)
+	go func() {
+		for i := 0; i < 3; i++ {
+			other <- i
+		}
+	}()
+	TEQ("", <-c, "started")
+	TEQ("", <-c, "deferred <nil>")
+	for i := 0; i < 3; i++ {
+		TEQ("", <-other, i) // other goroutines are unaffected
+	}
+	select {
+	case m := <-c:
+		TEQ("", m, "no more messages after Goexit")
+	default:
+	}
+}
+
+func shadowResult() (x int) {
+	x = 1
+	{
+		x := 2 // does not alter the named result
+		x++
+		_ = x
+	}
+	return
+}
+
+func testShadowing() {
+	x := 1
+	{
+		x := 2
+		x++
+		TEQ("", x, 3)
+		{
+			x := "inner" // a different type too
+			TEQ("", x, "inner")
+		}
+		TEQ("", x, 3)
+	}
+	TEQ("", x, 1)
+
+	if x := x + 10; x > 10 {
+		TEQ("", x, 11)
+	}
+	TEQ("", x, 1)
+
+	fs := []func() int{}
+	for x := 0; x < 3; x++ {
+		x := x * 2 // a new variable for each iteration
+		fs = append(fs, func() int { return x })
+	}
+	for i, f := range fs {
+		TEQ("", f(), i*2)
+	}
+	TEQ("", x, 1)
+
+	inc := func() { x++ } // captures the outer x
+	{
+		x := 100
+		inc()
+		TEQ("", x, 100)
+	}
+	TEQ("", x, 2)
+
+	p := &x
+	{
+		x := 5
+		*p = x
+		TEQ("", x, 5)
+	}
+	TEQ("", x, 5)
+
+	TEQ("", shadowResult(), 1)
+}
+
+// The Go 1.4 library has no %w verb or errors.Is, so wrapping is tested using the same Unwrap convention by hand.
+var errSentinel = errors.New("sentinel")
+
+type wrapErr struct {
+	msg string
+	err error
+}
+
+func (w *wrapErr) Error() string { return w.msg + ": " + w.err.Error() }
+func (w *wrapErr) Unwrap() error { return w.err }
+
+func errorIs(err, target error) bool {
+	for err != nil {
+		if err == target {
+			return true
+		}
+		u, ok := err.(interface {
+			Unwrap() error
+		})
+		if !ok {
+			return false
+		}
+		err = u.Unwrap()
+	}
+	return false
+}
+
+func testErrorWrap() {
+	var err error = &wrapErr{"outer", &wrapErr{fmt.Sprintf("inner %d", 42), errSentinel}}
+	TEQ("", err.Error(), "outer: inner 42: sentinel")
+	TEQ("", errorIs(err, errSentinel), true)
+	TEQ("", errorIs(err, errors.New("sentinel")), false)
+	TEQ("", errorIs(fmt.Errorf("%v", errSentinel), errSentinel), false)
+}
+
 func testDefer_a() {
 	i := 0
 	defer TEQ("", i, 0)
@@ -1415,6 +2918,26 @@ func testDefer() {
 	TEQ("", testDefer_c(), 2)
 	protect(g)
 	TEQ("", tddCount, 6)
+	TEQ("", recoverNormal(), 42)
+	TEQ("", recoverTwice(), "boom,<nil>")
+}
+
+// recover returns nil when there is no panic, and the function returns as normal
+func recoverNormal() (r int) {
+	defer func() {
+		if x := recover(); x != nil {
+			r = -1
+		}
+	}()
+	return 42
+}
+
+// only the first recover during a panic sees its value
+func recoverTwice() (r string) {
+	defer func() {
+		r = fmt.Sprint(recover(), ",", recover())
+	}()
+	panic("boom")
 }
 
 // these two names were failing in java as being duplicates, now failing in PHP...
@@ -1524,6 +3047,71 @@ func testChanSelect() {
 	//time.Sleep(2 * 1e9)
 }
 
+// a select with a single case is built by ssa as the bare channel operation, blocking until it is ready
+func testSingleCaseSelect() {
+	c := make(chan int)
+	done := make(chan bool)
+	go func() {
+		c <- 42
+		select {
+		case c <- 43:
+		}
+		close(c)
+	}()
+	select {
+	case v := <-c:
+		TEQ("testSingleCaseSelect() receive", v, 42)
+	}
+	select {
+	case v, ok := <-c:
+		TEQ("testSingleCaseSelect() send", v, 43)
+		TEQ("testSingleCaseSelect() send ok", ok, true)
+	}
+	select {
+	case v, ok := <-c:
+		TEQ("testSingleCaseSelect() closed", v, 0)
+		TEQ("testSingleCaseSelect() closed ok", ok, false)
+	}
+	go func() {
+		select {
+		case done <- true:
+		}
+	}()
+	select {
+	case <-done:
+	}
+}
+
+func selectCounts(n int) (counts [2]int) {
+	a, b := make(chan int, 1), make(chan int, 1)
+	for i := 0; i < n; i++ {
+		a <- 0
+		b <- 1
+		select { // both cases are always ready
+		case x := <-a:
+			counts[x]++
+			<-b
+		case x := <-b:
+			counts[x]++
+			<-a
+		}
+	}
+	return
+}
+
+func testSelectFairness() {
+	counts := selectCounts(1000)
+	if counts[0] < 400 || counts[1] < 400 { // over 6 standard deviations from the mean
+		fmt.Println("testSelectFairness() unfair choice of ready select cases", counts)
+	}
+	if runtime.GOOS == "nacl" { // really a haxe emulation of nacl
+		hx.Call("", "Scheduler.seedRandom", 1, 42)
+		first := selectCounts(100)
+		hx.Call("", "Scheduler.seedRandom", 1, 42)
+		TEQ("testSelectFairness() seeded", selectCounts(100), first)
+	}
+}
+
 //end code from http://golangtutorials.blogspot.co.uk/2011/06/channels-in-go-range-and-select.html
 
 //From the go tour http://tour.golang.org/#69
@@ -1646,6 +3234,23 @@ type foo2 struct {
 	}
 }
 
+func testArrayLen() {
+	var arr [5]int
+	var nilArr *[5]int // the length is in the type, so a nil array pointer is never dereferenced
+	TEQ("", len(arr), 5)
+	TEQ("", cap(arr), 5)
+	TEQ("", len(&arr), 5)
+	TEQ("", len(nilArr), 5)
+	TEQ("", cap(nilArr), 5)
+	n := 0
+	for i := range nilArr {
+		n += i
+	}
+	TEQ("", n, 0+1+2+3+4)
+	var grid [3][4]int
+	TEQ("", len(grid[2]), 4)
+}
+
 func testPtr() {
 	q := &bar().a
 	//fmt.Println("pointer created")
@@ -1670,6 +3275,29 @@ func testPtr() {
 	f3.a = [3]int{4, 4, 4}
 	TEQ("", s3[1], 4) // should be 4
 
+	// pointer-typed fields, read and written through the pointer
+	n := 7
+	pf := ptrFields{ip: &n}
+	TEQ("", *pf.ip, 7)
+	*pf.ip = 8
+	TEQ("", n, 8)
+	pf.next = &ptrFields{ip: pf.ip}
+	*pf.next.ip++
+	TEQ("", n, 9)
+	pf.next.ip = nil
+	TEQ("", pf.next.ip == nil, true)
+	TEQ("", pf.ip == &n, true)
+	pf.iface = pf.ip
+	pf.fn = func() int { return *pf.ip * 2 }
+	TEQ("", *(pf.iface.(*int)), 9)
+	TEQ("", pf.fn(), 18)
+}
+
+type ptrFields struct {
+	ip    *int
+	next  *ptrFields
+	iface interface{}
+	fn    func() int
 }
 
 type tbe struct {
@@ -1697,6 +3325,35 @@ func testEmbed() {
 	TEQ("", "GruntGruntGrunt", t.zip())
 }
 
+type uintptrLayout struct {
+	a, b int32
+	c    [4]int16
+}
+
+func testUintptrArith() {
+	s := uintptrLayout{a: 1, b: 2}
+	u := uintptr(unsafe.Pointer(&s))
+	pb := (*int32)(unsafe.Pointer(u + unsafe.Offsetof(s.b)))
+	*pb = 7
+	TEQint32("testUintptrArith() field via uintptr", s.b, 7)
+
+	base := uintptr(unsafe.Pointer(&s.c[0]))
+	for i := 0; i < len(s.c); i++ { // iterate over the array, as low-level code iterates over memory
+		*(*int16)(unsafe.Pointer(base + uintptr(i)*unsafe.Sizeof(s.c[0]))) = int16(i * 10)
+	}
+	TEQ("testUintptrArith() array via uintptr", s.c, [4]int16{0, 10, 20, 30})
+	last := uintptr(unsafe.Pointer(&s.c[3]))
+	TEQ("testUintptrArith() uintptr difference", last-base, uintptr(6))
+	TEQ("testUintptrArith() back from the end", *(*int16)(unsafe.Pointer(last - 4)), int16(10))
+
+	x := ^uintptr(0) - 0xf // whatever the word size
+	x += 0x20
+	TEQ("testUintptrArith() overflow", x, uintptr(0x10))
+	x -= 0x20
+	TEQ("testUintptrArith() underflow", x, ^uintptr(0)-0xf)
+	TEQ("testUintptrArith() low bits", x+1, ^uintptr(0)-0xe)
+}
+
 func testUnsafe() { // adapted from http://stackoverflow.com/questions/19721008/golang-unsafe-dynamic-byte-array
 
 	// Arbitrary size
@@ -1723,6 +3380,25 @@ func testUnsafe() { // adapted from http://stackoverflow.com/questions/19721008/
 	//uip++
 }
 
+type unsafeLayout struct {
+	a byte
+	b int64
+	c int32
+}
+
+func testUnsafeSizes() {
+	var ul unsafeLayout
+	TEQ("", unsafe.Offsetof(ul.a), uintptr(0))
+	TEQ("", unsafe.Offsetof(ul.b), uintptr(8))
+	TEQ("", unsafe.Offsetof(ul.c), uintptr(16))
+	TEQ("", unsafe.Alignof(ul.b), uintptr(8))
+	if runtime.GOOS == "nacl" { // the word size is 4 and, as for Object.make(), no trailing padding
+		TEQ("", unsafe.Sizeof(ul), uintptr(20))
+		TEQ("", unsafe.Sizeof(int(0)), uintptr(4))
+		TEQ("", unsafe.Sizeof(""), uintptr(8))
+	}
+}
+
 func tc64(f float64) float64 {
 	if runtime.GOOS == "nacl" {
 		return hx.CallFloat("", "Go_haxegoruntime_FFloat64frombits.hx", 1,
@@ -1767,6 +3443,255 @@ func testFloatConv() {
 	}
 }
 
+// each float32 operation must round to float32, as accumulating in double precision gives different results
+func testFloat32Arith() {
+	var tenth float32
+	for i := 0; i < 10; i++ {
+		tenth += 0.1
+	}
+	TEQ("testFloat32Arith() sum of tenths", tenth, float32(1.0000001))
+
+	var third float32
+	for i := 0; i < 100000; i++ {
+		third += float32(1.0 / 3.0)
+	}
+	TEQ("testFloat32Arith() sum of thirds", third, float32(33356.555))
+
+	big := float32(16777216) // 2**24, above which float32 can't represent every integer
+	bigPlus := big + 1
+	TEQ("testFloat32Arith() 2**24+1", bigPlus, big)
+	if bigPlus != big {
+		fmt.Println("testFloat32Arith() 2**24+1 != 2**24")
+	}
+	TEQ("testFloat32Arith() -(2**24+1)", -bigPlus, float32(-16777216))
+}
+
+func testMathFuncs() {
+	const tiny = 1e-15 // the target's maths library may differ from Go's in the last bit
+	TEQfloat("testMathFuncs() Sqrt", math.Sqrt(2), 1.4142135623730951, tiny)
+	TEQfloat("testMathFuncs() Pow", math.Pow(2, 0.5), 1.4142135623730951, tiny)
+	TEQfloat("testMathFuncs() Sin", math.Sin(1), 0.8414709848078965, tiny)
+	TEQfloat("testMathFuncs() Cos", math.Cos(1), 0.54030230586813977, tiny)
+	TEQfloat("testMathFuncs() Exp", math.Exp(1), 2.7182818284590451, tiny)
+	TEQfloat("testMathFuncs() Log", math.Log(10), 2.3025850929940459, tiny)
+	TEQ("testMathFuncs() Floor", math.Floor(-1.5), -2.0)
+	TEQ("testMathFuncs() Ceil", math.Ceil(-1.5), -1.0)
+	TEQ("testMathFuncs() Abs", math.Abs(-3.25), 3.25)
+	TEQ("testMathFuncs() Mod", math.Mod(7, -3), 1.0)
+	TEQ("testMathFuncs() Mod -ve", math.Mod(-7, 3), -1.0)
+	TEQ("testMathFuncs() Pow int", math.Pow(2, 10), 1024.0)
+	TEQ("testMathFuncs() Pow -ve", math.Pow(-2, 3), -8.0)
+
+	// special values
+	negZero := math.Copysign(0, -1)
+	TEQ("testMathFuncs() Sqrt(-1)", math.IsNaN(math.Sqrt(-1)), true)
+	TEQ("testMathFuncs() Sqrt(-0)", math.Signbit(math.Sqrt(negZero)), true)
+	TEQ("testMathFuncs() Sqrt(+Inf)", math.IsInf(math.Sqrt(math.Inf(1)), 1), true)
+	TEQ("testMathFuncs() Pow(-8,1/3)", math.IsNaN(math.Pow(-8, 1.0/3)), true)
+	TEQ("testMathFuncs() Pow(0,-1)", math.IsInf(math.Pow(0, -1), 1), true)
+	TEQ("testMathFuncs() Pow(NaN,0)", math.Pow(math.NaN(), 0), 1.0)
+	TEQ("testMathFuncs() Pow(1,NaN)", math.Pow(1, math.NaN()), 1.0)
+	TEQ("testMathFuncs() Pow(-1,Inf)", math.Pow(-1, math.Inf(1)), 1.0)
+	TEQ("testMathFuncs() Sin(-0)", math.Signbit(math.Sin(negZero)), true)
+	TEQ("testMathFuncs() Sin(Inf)", math.IsNaN(math.Sin(math.Inf(1))), true)
+	TEQ("testMathFuncs() Cos(-Inf)", math.IsNaN(math.Cos(math.Inf(-1))), true)
+	TEQ("testMathFuncs() Exp(-Inf)", math.Exp(math.Inf(-1)), 0.0)
+	TEQ("testMathFuncs() Exp(+Inf)", math.IsInf(math.Exp(math.Inf(1)), 1), true)
+	TEQ("testMathFuncs() Log(0)", math.IsInf(math.Log(0), -1), true)
+	TEQ("testMathFuncs() Log(-1)", math.IsNaN(math.Log(-1)), true)
+	TEQ("testMathFuncs() Floor(-0)", math.Signbit(math.Floor(negZero)), true)
+	TEQ("testMathFuncs() Floor(-Inf)", math.IsInf(math.Floor(math.Inf(-1)), -1), true)
+	TEQ("testMathFuncs() Ceil(-0.5)", math.Signbit(math.Ceil(-0.5)), true)
+	TEQ("testMathFuncs() Ceil(NaN)", math.IsNaN(math.Ceil(math.NaN())), true)
+	TEQ("testMathFuncs() Abs(-Inf)", math.IsInf(math.Abs(math.Inf(-1)), 1), true)
+	TEQ("testMathFuncs() Mod(x,0)", math.IsNaN(math.Mod(1, 0)), true)
+	TEQ("testMathFuncs() Mod(x,Inf)", math.Mod(5, math.Inf(1)), 5.0)
+}
+
+type celsiusSlice []celsius
+
+func testAppendConversion() {
+	f := append([]float64{}, 1, 2)
+	TEQ("testAppendConversion() float64 len", len(f), 2)
+	TEQ("testAppendConversion() float64 values", f[0]+f[1]/4, 1.5)
+	const big = 1 << 40
+	f = append(f, big, 'a')
+	TEQ("testAppendConversion() float64 large const", f[2], float64(1<<40))
+	TEQ("testAppendConversion() float64 rune const", f[3], 97.0)
+	f32 := append([]float32(nil), 1, 3)
+	TEQ("testAppendConversion() float32", f32[0]/f32[1], float32(1)/float32(3))
+	b := append([]byte{}, 'x', 200)
+	TEQ("testAppendConversion() byte", string(b[:1]), "x")
+	TEQ("testAppendConversion() byte 200", b[1], byte(200))
+	u := append([]uint64{}, 1<<63)
+	TEQ("testAppendConversion() uint64", u[0]>>62, uint64(2))
+	c := append(celsiusSlice{}, 20, 37.5)
+	TEQ("testAppendConversion() named", c[1]-c[0], celsius(17.5))
+	var i []interface{}
+	i = append(i, 1, 2.5, "s")
+	_, isInt := i[0].(int)
+	_, isFloat := i[1].(float64)
+	TEQ("testAppendConversion() interface default types", isInt && isFloat, true)
+	cx := append([]complex128{}, 2)
+	TEQ("testAppendConversion() complex", real(cx[0]), 2.0)
+}
+
+func testCommaOkRecvLoop() {
+	ch := make(chan int, 5)
+	for i := 1; i <= 5; i++ {
+		ch <- i
+	}
+	close(ch)
+	sum, count := 0, 0
+	for v, ok := <-ch; ok; v, ok = <-ch {
+		sum += v
+		count++
+	}
+	TEQ("testCommaOkRecvLoop() all values", sum, 15)
+	TEQ("testCommaOkRecvLoop() count", count, 5)
+	unbuf := make(chan string)
+	go func() {
+		unbuf <- "a"
+		unbuf <- "b"
+		close(unbuf)
+	}()
+	got := ""
+	var s string
+	var ok bool
+	for s, ok = <-unbuf; ok; s, ok = <-unbuf {
+		got += s
+	}
+	TEQ("testCommaOkRecvLoop() unbuffered", got, "ab")
+	TEQ("testCommaOkRecvLoop() zero value on close", s, "")
+}
+
+const arrayDim = 3
+
+func testConstArraySize() {
+	var a [2 * arrayDim]int
+	TEQ("testConstArraySize() len", len(a), 6)
+	TEQ("testConstArraySize() sizeof", unsafe.Sizeof(a), 6*unsafe.Sizeof(a[0]))
+	var b [len("abc") + arrayDim<<1]byte
+	b[len(b)-1] = 'z'
+	TEQ("testConstArraySize() len of expression", len(b), 9)
+	TEQ("testConstArraySize() last element", b[8], byte('z'))
+	c := [...]int16{arrayDim*arrayDim - 1: 1}
+	TEQ("testConstArraySize() keyed len", len(c), 9)
+	TEQ("testConstArraySize() keyed sizeof", unsafe.Sizeof(c), uintptr(18))
+}
+
+type stackVec struct{ x, y int }
+
+func testStackAlloc() {
+	t := 0
+	var kept []*stackVec
+	for i := 0; i < 10; i++ {
+		v := stackVec{i, 1} // address does not escape, so re-uses the same space
+		p := &v
+		p.x++
+		TEQ("testStackAlloc() cleared each iteration", p.x+p.y, i+2)
+		t += p.x
+		w := stackVec{i, i} // address escapes, so must be a new allocation each time
+		kept = append(kept, &w)
+	}
+	TEQ("testStackAlloc() sum", t, 55)
+	TEQ("testStackAlloc() escaped pointers distinct", kept[3].x+kept[9].y, 12)
+	a := [3]int{}
+	q := &a[1]
+	*q = 7
+	TEQ("testStackAlloc() array element", a[1], 7)
+}
+
+func testAppendGrowth() {
+	var s []int
+	grown := 0
+	for i := 0; i < 100000; i++ {
+		c := cap(s)
+		s = append(s, i)
+		if cap(s) != c {
+			grown++
+		}
+		if cap(s) < len(s) {
+			TEQ("testAppendGrowth() cap less than len", cap(s), len(s))
+			return
+		}
+	}
+	if grown > 50 { // about 10 doublings to 1024, then 25pc growth each time, never once per append
+		TEQ("testAppendGrowth() capacity increases", grown, 50)
+	}
+	if cap(s) >= 3*len(s) {
+		TEQ("testAppendGrowth() capacity", cap(s), len(s))
+	}
+	TEQ("testAppendGrowth() contents", s[99999]+s[512], 99999+512)
+	b := append([]byte(nil), make([]byte, 100)...)
+	TEQ("testAppendGrowth() appending more than double", cap(b) >= 100, true)
+	t := make([]int, 5, 5)
+	t = append(t, 1)
+	TEQ("testAppendGrowth() small slice at least doubles", cap(t) >= 10, true)
+}
+
+func testUnicode() {
+	for _, tst := range []struct {
+		r                               rune
+		letter, digit, space, upp, lowr bool
+		upper, lower                    rune
+	}{
+		{'a', true, false, false, false, true, 'A', 'a'},
+		{'Z', true, false, false, true, false, 'Z', 'z'},
+		{'7', false, true, false, false, false, '7', '7'},
+		{' ', false, false, true, false, false, ' ', ' '},
+		{'\t', false, false, true, false, false, '\t', '\t'},
+		{'_', false, false, false, false, false, '_', '_'},
+		{'é', true, false, false, false, true, 'É', 'é'},
+		{'Ω', true, false, false, true, false, 'Ω', 'ω'},
+		{'ж', true, false, false, false, true, 'Ж', 'ж'},
+		{'ß', true, false, false, false, true, 'ß', 'ß'}, // no single rune upper case
+		{'世', true, false, false, false, false, '世', '世'},
+		{'٣', false, true, false, false, false, '٣', '٣'}, // Arabic-Indic digit three
+		{'\u00a0', false, false, true, false, false, '\u00a0', '\u00a0'},
+		{'\u2003', false, false, true, false, false, '\u2003', '\u2003'}, // em space
+		{'€', false, false, false, false, false, '€', '€'},
+		{'𝔸', true, false, false, true, false, '𝔸', '𝔸'}, // outside the BMP
+	} {
+		l := "testUnicode() " + string(tst.r) + " "
+		TEQ(l+"IsLetter", unicode.IsLetter(tst.r), tst.letter)
+		TEQ(l+"IsDigit", unicode.IsDigit(tst.r), tst.digit)
+		TEQ(l+"IsSpace", unicode.IsSpace(tst.r), tst.space)
+		TEQ(l+"IsUpper", unicode.IsUpper(tst.r), tst.upp)
+		TEQ(l+"IsLower", unicode.IsLower(tst.r), tst.lowr)
+		TEQ(l+"ToUpper", unicode.ToUpper(tst.r), tst.upper)
+		TEQ(l+"ToLower", unicode.ToLower(tst.r), tst.lower)
+	}
+	TEQ("testUnicode() ToTitle", unicode.ToTitle('ǆ'), 'ǅ')
+	TEQ("testUnicode() SimpleFold", unicode.SimpleFold('K'), 'k')
+}
+
+func testMathIntrinsics() {
+	negZero := math.Copysign(0, -1)
+	nan := math.NaN()
+	sqrt, floor, abs := math.Sqrt, math.Floor, math.Abs // called as values, so not as intrinsics
+	for _, x := range []float64{2, 0.25, 2.5, -2.5, 0, negZero, nan, math.Inf(1), math.Inf(-1), -1e300} {
+		TEQ("testMathIntrinsics() Sqrt", math.Float64bits(math.Sqrt(x)) == math.Float64bits(sqrt(x)) ||
+			(math.IsNaN(math.Sqrt(x)) && math.IsNaN(sqrt(x))), true)
+		TEQ("testMathIntrinsics() Floor", math.Float64bits(math.Floor(x)) == math.Float64bits(floor(x)) ||
+			(math.IsNaN(math.Floor(x)) && math.IsNaN(floor(x))), true)
+		TEQ("testMathIntrinsics() Abs", math.Float64bits(math.Abs(x)) == math.Float64bits(abs(x)) ||
+			(math.IsNaN(math.Abs(x)) && math.IsNaN(abs(x))), true)
+	}
+	TEQ("testMathIntrinsics() Sqrt(NaN)", math.IsNaN(math.Sqrt(nan)), true)
+	TEQ("testMathIntrinsics() Sqrt(-Inf)", math.IsNaN(math.Sqrt(math.Inf(-1))), true)
+	TEQ("testMathIntrinsics() Sqrt(-0)", math.Signbit(math.Sqrt(negZero)), true)
+	TEQ("testMathIntrinsics() Sqrt(0.25)", math.Sqrt(0.25), 0.5)
+	TEQ("testMathIntrinsics() Floor(NaN)", math.IsNaN(math.Floor(nan)), true)
+	TEQ("testMathIntrinsics() Floor(-0)", math.Signbit(math.Floor(negZero)), true)
+	TEQ("testMathIntrinsics() Floor(-2.5)", math.Floor(-2.5), -3.0)
+	TEQ("testMathIntrinsics() Abs(NaN)", math.IsNaN(math.Abs(nan)), true)
+	TEQ("testMathIntrinsics() Abs(-0)", math.Signbit(math.Abs(negZero)), false)
+	TEQ("testMathIntrinsics() Abs(-Inf)", math.IsInf(math.Abs(math.Inf(-1)), 1), true)
+	TEQ("testMathIntrinsics() Ceil(-0.5)", math.Signbit(math.Ceil(-0.5)), true)
+	TEQ("testMathIntrinsics() Ceil(2.5)", math.Ceil(2.5), 3.0)
+}
+
 type ObjKey [2]int
 
 func testObjMap() {
@@ -1819,6 +3744,20 @@ func testUnaligned() {
 	}
 }
 
+type twoMethods int
+
+func (twoMethods) Zeta() int  { return 26 } // declared first, but must be listed second
+func (twoMethods) Alpha() int { return 1 }
+
+func testReflectMethods() {
+	var v twoMethods
+	t := reflect.TypeOf(v)
+	TEQ("", t.NumMethod(), 2)
+	TEQ("", t.Method(0).Name, "Alpha")
+	TEQ("", t.Method(1).Name, "Zeta")
+	TEQ("", reflect.TypeOf(&v).NumMethod(), 2) // the pointer's method set includes the value methods
+}
+
 func main() {
 	var array [4][5]int
 	array[3][2] = 12
@@ -1840,11 +3779,15 @@ func main() {
 	testCopy()
 	testInFuncPtr()
 	testCallBy()
+	testCallByBigValue()
 	testMap()
+	testMapRangeDelete()
 	testNamed()
 	testFuncPtr()
 	testIntOverflow()
+	testNarrowing()
 	testSlices()
+	testThreeIndexSlices()
 	testChan()
 	testComplex()
 	testUTF8()
@@ -1857,21 +3800,73 @@ func main() {
 	testInterfaceMethods()
 	testStrconv()
 	testTour64()
+	testGoMethodValue()
+	testErrorWrap()
+	testMixedReceivers()
+	testInterfaceEquality()
+	testTypeSwitchNil()
+	testStringerFormat()
+	testArrayConversion()
+	testCompositeLitAddr()
+	testNakedReturn()
+	testMixedReturn()
+	testMethodExpr()
+	testPromotedAssert()
+	testNamedFuncConv()
+	testGoexit()
+	testShadowing()
 	testUintDiv32()
 	testUintDiv64()
 	testDefer()
 	testPtr()
+	testArrayLen()
 	testChanSelect()
+	testSingleCaseSelect()
+	testSelectFairness()
 	testEmbed()
 	testUnsafe()
+	testUintptrArith()
+	testUnsafeSizes()
 	testObjMap()
 	testFloatConv()
+	testFloat32Arith()
+	testMathFuncs()
+	testMathIntrinsics()
+	testUnicode()
+	testAppendGrowth()
+	testAppendConversion()
+	testStackAlloc()
+	testConstArraySize()
+	testCommaOkRecvLoop()
+	testChainedTypeAssert()
+	testShiftWidth()
+	testNestedFieldAddr()
+	testIndexAddrAliases()
+	testVariadicInvoke()
+	testMapLookupZero()
+	testNilInterface()
+	testCappedAppend()
+	testFuncNil()
+	testEmbeddedInterfaceMethods()
+	testStructTagEscapes()
+	testIfaceFieldZero()
+	testTypedNils()
+	testVariadicSpread()
+	testMapValueMethod()
+	testDeferArgs()
+	testStringIndexByte()
+	testSignedDivMod()
+	testSubsliceAliasing()
 	testUnaligned()
+	testReflectMethods()
 	//aGrWG.Wait()
 	TEQint32(""+" testManyGoroutines() (NOT sync/atomic) counter:", aGrCtr, 0)
 	if runtime.GOOS == "nacl" { // really a haxe emulation of nacl
 		TEQ("", hx.CodeInt("", "42;"), int(42))
 		TEQ("", hx.CodeString("", "'test';"), "test")
+		TEQ("", hx.CodeInt("", "Go.main_BBlue;"), Blue) // as emitted by NamedConst
+		TEQ("", hx.CodeInt("", "Go.main_SSmall;"), Small)
+		TEQ("", hx.CodeInt("", "Go.main_LLarge;"), Large)
 		TEQ(""+"Num Haxe GR post-wait", runtime.NumGoroutine(), 1)
 		//panic("show GRs active")
 	} else {
@@ -0,0 +1,18 @@
+// Copyright 2014 Elliott Stoneham and The TARDIS Go Authors
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+// Test that the source names of local variables are emitted in debug mode.
+package main
+
+func sum(limit int) int {
+	total := 0
+	for counter := 0; counter < limit; counter++ {
+		total += counter
+	}
+	return total
+}
+
+func main() {
+	println(sum(10))
+}
@@ -0,0 +1,22 @@
+// +build ignore
+
+package main
+
+import (
+	"fmt"
+	"runtime"
+)
+
+func div(x, y int) (q int, r interface{}) {
+	defer func() {
+		r = recover()
+	}()
+	return x / y, nil
+}
+
+func main() {
+	q, r := div(7, 0)
+	_, ok := r.(runtime.Error)
+	fmt.Println(q, r, ok)
+	fmt.Println("int division by zero recovered")
+}
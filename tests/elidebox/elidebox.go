@@ -0,0 +1,19 @@
+package main
+
+type pair struct{ a, b int }
+
+var stored interface{}
+
+func unboxed(p pair) pair {
+	return interface{}(p).(pair) // the box is never seen, so can be elided
+}
+
+func kept(p pair) pair {
+	i := interface{}(p)
+	stored = i // the box escapes, so must be made
+	return i.(pair)
+}
+
+func main() {
+	println(unboxed(pair{1, 2}).b, kept(pair{3, 4}).a, stored.(pair).b)
+}
@@ -0,0 +1,65 @@
+package main
+
+// round-trips integers through encoding/binary in both byte orders,
+// the results must not depend on the byte layout of the target's memory
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+var failed = 0
+
+func check(what string, ok bool) {
+	if !ok {
+		println("FAIL", what)
+		failed++
+	}
+}
+
+func main() {
+	b := make([]byte, 8)
+
+	binary.BigEndian.PutUint16(b, 0x1234)
+	check("BigEndian.PutUint16 layout", b[0] == 0x12 && b[1] == 0x34)
+	check("BigEndian.Uint16", binary.BigEndian.Uint16(b) == 0x1234)
+	binary.LittleEndian.PutUint16(b, 0x1234)
+	check("LittleEndian.PutUint16 layout", b[0] == 0x34 && b[1] == 0x12)
+	check("LittleEndian.Uint16", binary.LittleEndian.Uint16(b) == 0x1234)
+
+	binary.BigEndian.PutUint32(b, 0xdeadbeef)
+	check("BigEndian.PutUint32 layout", b[0] == 0xde && b[3] == 0xef)
+	check("BigEndian.Uint32", binary.BigEndian.Uint32(b) == 0xdeadbeef)
+	check("BigEndian.Uint32 of fixed bytes", binary.BigEndian.Uint32([]byte{0, 0, 1, 2}) == 258)
+	binary.LittleEndian.PutUint32(b, 0xdeadbeef)
+	check("LittleEndian.PutUint32 layout", b[0] == 0xef && b[3] == 0xde)
+	check("LittleEndian.Uint32", binary.LittleEndian.Uint32(b) == 0xdeadbeef)
+
+	binary.BigEndian.PutUint64(b, 0x0102030405060708)
+	check("BigEndian.PutUint64 layout", b[0] == 1 && b[7] == 8)
+	check("BigEndian.Uint64", binary.BigEndian.Uint64(b) == 0x0102030405060708)
+	binary.LittleEndian.PutUint64(b, 0xfedcba9876543210)
+	check("LittleEndian.PutUint64 layout", b[0] == 0x10 && b[7] == 0xfe)
+	check("LittleEndian.Uint64", binary.LittleEndian.Uint64(b) == 0xfedcba9876543210)
+
+	var buf bytes.Buffer
+	u16, u32, u64 := uint16(0xbeef), uint32(0xcafebabe), uint64(0x8000000000000001)
+	for _, order := range []binary.ByteOrder{binary.BigEndian, binary.LittleEndian} {
+		buf.Reset()
+		binary.Write(&buf, order, u16)
+		binary.Write(&buf, order, u32)
+		binary.Write(&buf, order, u64)
+		check(order.String()+" Write length", buf.Len() == 14)
+		var r16 uint16
+		var r32 uint32
+		var r64 uint64
+		binary.Read(&buf, order, &r16)
+		binary.Read(&buf, order, &r32)
+		binary.Read(&buf, order, &r64)
+		check(order.String()+" Read uint16", r16 == u16)
+		check(order.String()+" Read uint32", r32 == u32)
+		check(order.String()+" Read uint64", r64 == u64)
+	}
+
+	println("endian failures:", failed)
+}
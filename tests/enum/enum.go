@@ -0,0 +1,23 @@
+package main
+
+// the exported constants of these types are emitted as Haxe enum abstracts
+const tardisgoEnumList = "Color"
+
+type Color int
+
+const (
+	Red Color = iota
+	Green
+	Blue
+)
+
+// Answer is not of an enum type, so keeps its public static var
+const Answer = 42
+
+func (c Color) String() string {
+	return [...]string{"red", "green", "blue"}[c]
+}
+
+func main() {
+	println(Red.String(), Blue.String(), Answer)
+}
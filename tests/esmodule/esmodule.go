@@ -0,0 +1,10 @@
+package main
+
+// Exported is listed in the ES module exports
+func Exported(a int) int { return unexported(a) + 1 }
+
+func unexported(a int) int { return a * 2 }
+
+func main() {
+	println(Exported(20))
+}
@@ -0,0 +1,12 @@
+// +build ignore
+
+// This program should not compile, as function values may only be compared to nil.
+
+package main
+
+func one() int { return 1 }
+
+func main() {
+	f, g := one, one
+	println(f == g)
+}
@@ -0,0 +1,8 @@
+package main
+
+// Zero-sized elements take no memory, so a slice of them can be long enough for append to overflow int.
+func main() {
+	s := make([]struct{}, 1<<30)
+	s = append(s, s...)
+	println("grew to", len(s))
+}
@@ -0,0 +1,16 @@
+// Package a has a package-level variable derived from one in package b.
+package a
+
+import "github.com/tardisgo/tardisgo/tests/initorder/b"
+
+// Derived is initialized from b.Base, after package b has been initialized.
+var Derived = derive()
+
+func derive() int {
+	b.Log = append(b.Log, "a.Derived")
+	return b.Base + 1
+}
+
+func init() {
+	b.Log = append(b.Log, "a.init")
+}
@@ -0,0 +1,25 @@
+// Package b is imported by package a, so it must be initialized first.
+package b
+
+// Log records the order in which the package-level variables and init functions of the test run.
+var Log []string
+
+// Base is declared before Seed, but depends on it, so Seed must be initialized first.
+var Base = scale(Seed)
+
+// Seed is the starting value.
+var Seed = record("b.Seed", 21)
+
+func scale(n int) int {
+	record("b.Base", 0)
+	return n * 2
+}
+
+func record(what string, n int) int {
+	Log = append(Log, what)
+	return n
+}
+
+func init() {
+	Log = append(Log, "b.init")
+}
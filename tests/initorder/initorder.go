@@ -0,0 +1,19 @@
+// Test that package-level variables are initialized in dependency order, across packages, before the init functions.
+package main
+
+import (
+	"fmt"
+
+	"github.com/tardisgo/tardisgo/tests/initorder/a"
+	"github.com/tardisgo/tardisgo/tests/initorder/b"
+)
+
+var fromA = a.Derived * 10
+
+func init() {
+	b.Log = append(b.Log, "main.init")
+}
+
+func main() {
+	fmt.Println(fromA, a.Derived, b.Base, b.Seed, b.Log)
+}
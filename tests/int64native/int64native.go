@@ -0,0 +1,67 @@
+package main
+
+// The int64 operations and conversions here are emitted as native integer code by -native64, and through the GOint64 emulation otherwise,
+// so the program must print the same in both modes.
+
+var signed = []int64{0, 1, -1, 2, -2, 7, 1 << 31, -1 << 31, 1<<32 + 5, 1<<63 - 1, -1 << 63, 0x123456789abcdef, -0x5555555555555555}
+
+func mix(h uint64, v uint64) uint64 {
+	h ^= v
+	h *= 0x100000001b3
+	return h
+}
+
+// conv narrows and widens x through smaller integer types, which -native64 also converts natively
+func conv(x int64) uint64 {
+	a := int64(int32(x))
+	b := int64(uint32(x))
+	c := uint64(int8(x))
+	d := int64(uint16(x))
+	return uint64(a) ^ uint64(b)<<1 ^ c<<2 ^ uint64(d)<<3
+}
+
+func main() {
+	var h uint64 = 0xcbf29ce484222325
+	for _, x := range signed {
+		h = mix(h, uint64(-x))
+		h = mix(h, uint64(^x))
+		h = mix(h, uint64(x<<1))
+		h = mix(h, uint64(x<<33))
+		h = mix(h, uint64(x>>7))
+		h = mix(h, uint64(x>>40))
+		h = mix(h, uint64(x)>>7)
+		h = mix(h, uint64(x)>>63)
+		h = mix(h, conv(x))
+		for _, y := range signed {
+			h = mix(h, uint64(x+y))
+			h = mix(h, uint64(x-y))
+			h = mix(h, uint64(x*y))
+			h = mix(h, uint64(x&y))
+			h = mix(h, uint64(x|y))
+			h = mix(h, uint64(x^y))
+			h = mix(h, uint64(x&^y))
+			if y != 0 {
+				h = mix(h, uint64(x/y))
+				h = mix(h, uint64(x%y))
+				h = mix(h, uint64(x)/uint64(y))
+			}
+			if x < y {
+				h = mix(h, 1)
+			}
+			if x >= y {
+				h = mix(h, 2)
+			}
+			if uint64(x) < uint64(y) {
+				h = mix(h, 3)
+			}
+			if x == y {
+				h = mix(h, 4)
+			}
+		}
+	}
+	if h != 1808987020491053661 { // the result when compiled by Go
+		println("int64 hash mismatch", h)
+		return
+	}
+	println("int64 ok")
+}
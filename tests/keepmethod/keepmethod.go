@@ -0,0 +1,18 @@
+package main
+
+// the Area method is only ever called through an interface, so must survive Haxe dead code elimination
+
+type shape interface {
+	Area() int
+}
+
+type rect struct{ w, h int }
+
+func (r rect) Area() int { return r.w * r.h }
+
+func perimeter(r rect) int { return 2 * (r.w + r.h) }
+
+func main() {
+	var s shape = rect{3, 4}
+	println(s.Area(), perimeter(rect{3, 4}))
+}
@@ -0,0 +1,20 @@
+package main
+
+// a function, a method and a closure, for the function manifest
+
+type counter int
+
+func (c *counter) inc(by int) int {
+	*c += counter(by)
+	return int(*c)
+}
+
+func twice(f func() int) int {
+	f()
+	return f()
+}
+
+func main() {
+	var c counter
+	println(twice(func() int { return c.inc(2) }))
+}
@@ -0,0 +1,10 @@
+// +build ignore
+
+// This program should not compile, as map elements are not addressable.
+
+package main
+
+func main() {
+	p := &map[string]int{"a": 1}["a"]
+	println(*p)
+}
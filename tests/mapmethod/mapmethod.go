@@ -0,0 +1,15 @@
+// +build ignore
+
+// This program should not compile, as a pointer-receiver method needs the address of m[k], which is not addressable.
+
+package main
+
+type counter struct{ n int }
+
+func (c *counter) inc() { c.n++ }
+
+func main() {
+	m := map[string]counter{"a": {}}
+	m["a"].inc()
+	println(m["a"].n)
+}
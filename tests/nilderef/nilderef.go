@@ -0,0 +1,49 @@
+// A nil pointer dereference must be a Go panic, which can be recovered.
+package main
+
+import (
+	"fmt"
+	"runtime"
+)
+
+type node struct {
+	val  int
+	next *node
+}
+
+func load(p *int) int {
+	return *p
+}
+
+func deref(p *int) (v int, r interface{}) {
+	defer func() {
+		r = recover()
+	}()
+	v = 1
+	v = load(p)
+	return
+}
+
+func field(n *node) (v int, r interface{}) {
+	defer func() {
+		r = recover()
+	}()
+	return n.next.val, nil
+}
+
+// runtimeError reports if r is a runtime.Error, as nil dereferences must be
+func runtimeError(r interface{}) bool {
+	_, ok := r.(runtime.Error)
+	return ok
+}
+
+func main() {
+	x := 42
+	v, r := deref(&x)
+	fmt.Println(v, r, runtimeError(r))
+	v, r = deref(nil)
+	fmt.Println(v, r, runtimeError(r))
+	v, r = field(&node{val: 1})
+	fmt.Println(v, r, runtimeError(r))
+	fmt.Println("still running")
+}
@@ -0,0 +1,12 @@
+package main
+
+type shape interface {
+	Area() int
+}
+
+func main() {
+	var s shape
+	println("nil interface", s == nil)
+	println(s.Area())
+	println("method call on a nil interface did not panic")
+}
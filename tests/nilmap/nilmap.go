@@ -0,0 +1,8 @@
+package main
+
+var counts map[string]int
+
+func main() {
+	counts["a"]++
+	println("assignment to a nil map did not panic")
+}
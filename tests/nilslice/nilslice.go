@@ -0,0 +1,9 @@
+package main
+
+var items []int
+
+func main() {
+	i := 1
+	println(items[i])
+	println("index of a nil slice did not panic")
+}
@@ -0,0 +1,15 @@
+package main
+
+// signed integer overflow wraps, unless overflow trapping is on
+
+var one int8 = 1
+var big int64 = 1 << 62
+
+func main() {
+	var a int8 = 127
+	b := a + one
+	c := big * 2
+	var u uint8 = 255
+	u += uint8(one) // unsigned arithmetic always wraps
+	println("wrapped", b, c, u)
+}
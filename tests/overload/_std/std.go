@@ -0,0 +1,7 @@
+// Package _std is a hand-written Go stub for part of the Haxe standard library,
+// the leading underscore marks it as being implemented in Haxe, not Go.
+package _std
+
+// StringTools.hex(n:Int,?digits:Int) has two forms
+func XStringTools_hex_1(n int) string         { return "" }
+func XStringTools_hex_2(n, digits int) string { return "" }
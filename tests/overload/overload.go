@@ -0,0 +1,9 @@
+package main
+
+import "github.com/tardisgo/tardisgo/tests/overload/_std"
+
+// calls both forms of an overloaded Haxe function
+func main() {
+	println(_std.XStringTools_hex_1(255))
+	println(_std.XStringTools_hex_2(255, 4))
+}
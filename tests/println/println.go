@@ -0,0 +1,12 @@
+package main
+
+func main() {
+	var p *int
+	println("ints", 42, -7, uint8(255), uint32(4294967295), int64(-1)<<40, ^uint64(0))
+	println("bool", true, false)
+	println("nil", p, []int(nil))
+	print("no", "spaces", 1, true, "\n")
+	x := 5
+	p = &x
+	println("pointer", p)
+}
@@ -0,0 +1,9 @@
+package main
+
+var a = [3]int{1, 2, 3}
+
+func main() {
+	s := a[:]
+	i := 5
+	println(s[i])
+}
@@ -0,0 +1,42 @@
+package main
+
+// reads a strings.Reader in chunks through the io.Reader interface,
+// and reassembles the content through the io.Writer interface
+
+import (
+	"bytes"
+	"io"
+	"strings"
+)
+
+const text = "The quick brown fox jumps over the lazy dog"
+
+func copyChunks(w io.Writer, r io.Reader, size int) (chunks int, err error) {
+	buf := make([]byte, size)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			chunks++
+			if _, werr := w.Write(buf[:n]); werr != nil {
+				return chunks, werr
+			}
+		}
+		if err == io.EOF {
+			return chunks, nil
+		}
+		if err != nil {
+			return chunks, err
+		}
+	}
+}
+
+func main() {
+	var out bytes.Buffer
+	chunks, err := copyChunks(&out, strings.NewReader(text), 5)
+	if err != nil {
+		panic(err)
+	}
+	var n int64
+	n, err = io.Copy(&out, strings.NewReader("!"))
+	println(out.String() == text+"!", chunks, n, err == nil)
+}
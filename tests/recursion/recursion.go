@@ -0,0 +1,18 @@
+package main
+
+// fib is a naive recursive Fibonacci, where the depth of recursion is n
+func fib(n uint32) (uint32, uint32) {
+	if n == 0 {
+		return 0, 1
+	}
+	a, b := fib(n - 1)
+	return b, a + b
+}
+
+// deep enough to overflow the host stack, unless recursion yields to the scheduler
+const depth = 100000
+
+func main() {
+	f, _ := fib(depth)
+	println(f)
+}
@@ -0,0 +1,37 @@
+package main
+
+import "time"
+
+// two goroutines take turns to increment a counter passed through a channel,
+// while a third chooses between them with select and sleeps,
+// so the log shows an interleaving that only repeats if the schedule is deterministic
+
+func worker(name string, counter chan int, log chan string, done chan bool) {
+	for i := 0; i < 5; i++ {
+		n := <-counter
+		log <- name
+		counter <- n + 1
+	}
+	done <- true
+}
+
+func main() {
+	counter := make(chan int, 1)
+	log := make(chan string)
+	done := make(chan bool)
+	go worker("a", counter, log, done)
+	go worker("b", counter, log, done)
+	counter <- 0
+	s := ""
+	for finished := 0; finished < 2; {
+		select {
+		case name := <-log:
+			s += name
+			time.Sleep(time.Millisecond)
+		case <-done:
+			finished++
+			s += "."
+		}
+	}
+	println(s, <-counter)
+}
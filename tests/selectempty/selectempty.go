@@ -0,0 +1,18 @@
+// Copyright 2014 Elliott Stoneham and The TARDIS Go Authors
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+// Test that select{} parks the main goroutine, while other goroutines continue,
+// and that a deadlock is reported once no goroutine can run.
+package main
+
+import "fmt"
+
+func main() {
+	go func() {
+		for i := 0; i < 3; i++ {
+			fmt.Println("working", i)
+		}
+	}()
+	select {}
+}
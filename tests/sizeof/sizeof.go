@@ -0,0 +1,22 @@
+package main
+
+import "unsafe"
+
+type layout struct {
+	a byte
+	b int64
+	c int32
+}
+
+var l layout
+
+const n = 3
+
+var a [2*n + len("ab")]int32
+
+func main() {
+	l.b = 42
+	println(unsafe.Sizeof(l), unsafe.Offsetof(l.b), unsafe.Alignof(l.b), l.b)
+	a[len(a)-1] = 7
+	println(len(a), unsafe.Sizeof(a), a[7])
+}
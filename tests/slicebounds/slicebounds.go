@@ -0,0 +1,45 @@
+package main
+
+import "github.com/tardisgo/tardisgo/haxe/hx"
+
+// Each invalid slice expression is chosen by a Haxe define, for example: haxe ... -D slice3
+// Without a define, only valid slice expressions are run.
+func main() {
+	s := make([]int, 2, 4)
+	arr := [3]int{1, 2, 3}
+	str := "abc"
+	var nilSlice []int
+	lo, hi, max := 0, 0, 0
+	switch {
+	case hx.CodeBool("", "#if slice1 true #else false #end ;"): // low > high
+		lo, hi = 2, 1
+		println(s[lo:hi])
+	case hx.CodeBool("", "#if slice2 true #else false #end ;"): // high > cap
+		lo, hi = 0, 5
+		println(s[lo:hi])
+	case hx.CodeBool("", "#if slice3 true #else false #end ;"): // low < 0
+		lo, hi = -1, 1
+		println(s[lo:hi])
+	case hx.CodeBool("", "#if slice4 true #else false #end ;"): // high < 0, of an array
+		lo, hi = 0, -1
+		println(arr[lo:hi])
+	case hx.CodeBool("", "#if slice5 true #else false #end ;"): // high > len, of a string
+		lo, hi = 1, 4
+		println(str[lo:hi])
+	case hx.CodeBool("", "#if slice6 true #else false #end ;"): // of a nil slice
+		lo, hi = 0, 1
+		println(nilSlice[lo:hi])
+	case hx.CodeBool("", "#if slice7 true #else false #end ;"): // max > cap
+		lo, hi, max = 0, 1, 5
+		println(s[lo:hi:max])
+	case hx.CodeBool("", "#if slice8 true #else false #end ;"): // high > max
+		lo, hi, max = 0, 2, 1
+		println(s[lo:hi:max])
+	case hx.CodeBool("", "#if slice9 true #else false #end ;"): // max > len, of an array
+		lo, hi, max = 0, 1, 4
+		println(arr[lo:hi:max])
+	default:
+		lo, hi, max = 1, 4, 4
+		println(len(s[lo:hi]), len(arr[lo-1:hi-1]), str[lo:hi-1], len(nilSlice[:0]), cap(s[lo:lo:max]))
+	}
+}
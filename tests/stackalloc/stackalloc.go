@@ -0,0 +1,43 @@
+package main
+
+// takes the address of a local in a tight loop, which should not allocate each iteration
+
+type vec struct{ x, y int }
+
+func (v *vec) add(w vec) {
+	v.x += w.x
+	v.y += w.y
+}
+
+func sumLocal(n int) int {
+	t := 0
+	for i := 0; i < n; i++ {
+		v := vec{i, 1}
+		p := &v
+		p.x++
+		t += p.x - i + p.y
+	}
+	return t
+}
+
+func keepAll(n int) []*vec {
+	var r []*vec
+	for i := 0; i < n; i++ {
+		v := vec{i, i}
+		r = append(r, &v)
+	}
+	return r
+}
+
+func sumMethod(n int) int {
+	t := vec{}
+	for i := 0; i < n; i++ {
+		t.add(vec{i, 1})
+	}
+	return t.x + t.y
+}
+
+func main() {
+	r := keepAll(3)
+	println(sumLocal(1000000), r[0].x, r[1].x, r[2].x, sumMethod(1000))
+}
@@ -0,0 +1,9 @@
+package main
+
+func main() {
+	s := "é!" // s[0] and s[1] are the two bytes of the UTF-8 encoding of é
+	println("bytes", int(s[0])+int(s[1]))
+	i := len(s)
+	println(s[i])
+	println("index past the end of a string did not panic")
+}
@@ -0,0 +1,17 @@
+// Package structcopy is compiled with -test -bench, to time passing a large struct by value on the target.
+package structcopy
+
+// Big is a struct large enough that passing it by value is a real copy.
+type Big struct {
+	ID    int
+	Name  string
+	Data  [64]int32
+	Ratio float64
+}
+
+// Touch changes its copy of b, which must not affect the caller's value.
+func Touch(b Big) int32 {
+	b.Data[7] += 100
+	b.ID = -1
+	return b.Data[7]
+}
@@ -0,0 +1,18 @@
+package structcopy
+
+import "testing"
+
+func TestTouch(t *testing.T) {
+	b := Big{ID: 1, Name: "big"}
+	b.Data[7] = 5
+	if Touch(b) != 105 || b.Data[7] != 5 || b.ID != 1 {
+		t.Errorf("the callee changed the caller's struct: %v %v", b.ID, b.Data[7])
+	}
+}
+
+func BenchmarkLargeStructCopy(b *testing.B) {
+	var big Big
+	for i := 0; i < b.N; i++ {
+		big.Data[7] = Touch(big) - 100
+	}
+}
@@ -0,0 +1,15 @@
+// +build haxe
+
+// This failing test is only built for the transpiled target, so that go test still passes on the host.
+
+package testharness
+
+import "testing"
+
+func TestBroken(t *testing.T) {
+	t.Run("fatal", func(t *testing.T) {
+		t.Fatalf("Add(1, 1) = %d, wanted 3\n", Add(1, 1))
+		t.Error("test carried on after Fatalf")
+	})
+	t.Run("after", func(t *testing.T) {}) // still runs
+}
@@ -0,0 +1,5 @@
+// Package testharness has two passing tests and one failing test, to check the reports of the -test harness.
+package testharness
+
+// Add adds
+func Add(a, b int) int { return a + b }
@@ -0,0 +1,23 @@
+package testharness
+
+import "testing"
+
+func TestAdd(t *testing.T) {
+	if Add(2, 3) != 5 {
+		t.Error("Add(2, 3) != 5")
+	}
+}
+
+func TestAddSubtests(t *testing.T) {
+	for _, tst := range []struct {
+		name       string
+		a, b, want int
+	}{{"zero", 0, 0, 0}, {"negative", -2, 1, -1}} {
+		tst := tst
+		t.Run(tst.name, func(t *testing.T) {
+			if got := Add(tst.a, tst.b); got != tst.want {
+				t.Errorf("Add(%d, %d) = %d", tst.a, tst.b, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,11 @@
+// Copyright 2014 Elliott Stoneham and The TARDIS Go Authors
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+// Package config defines a type with the same name as in the other config package.
+package config
+
+// Config is distinct from the other config.Config.
+type Config struct {
+	Name string
+}
@@ -0,0 +1,33 @@
+// Copyright 2014 Elliott Stoneham and The TARDIS Go Authors
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+// Test that types with the same short name, from different packages, have distinct type ids.
+package main
+
+import (
+	"fmt"
+
+	aconfig "github.com/tardisgo/tardisgo/tests/typeids/a/config"
+	bconfig "github.com/tardisgo/tardisgo/tests/typeids/b/config"
+
+	"github.com/tardisgo/tardisgo/haxe/hx"
+)
+
+func main() {
+	ida := hx.CallInt("", "TypeInfo.getId", 1, "github.com/tardisgo/tardisgo/tests/typeids/a/config.Config")
+	idb := hx.CallInt("", "TypeInfo.getId", 1, "github.com/tardisgo/tardisgo/tests/typeids/b/config.Config")
+	if ida == 0 || idb == 0 || ida == idb {
+		fmt.Println("type ids not distinct:", ida, idb)
+	}
+	if id := hx.CodeInt("", "_a.param(0).typ;", aconfig.Config{Name: "a"}); id != ida {
+		fmt.Println("a/config.Config has type id", id, "not", ida)
+	}
+	if id := hx.CodeInt("", "_a.param(0).typ;", bconfig.Config{Name: "b"}); id != idb {
+		fmt.Println("b/config.Config has type id", id, "not", idb)
+	}
+	// the short name is ambiguous, so must not resolve to either type
+	if id := hx.CallInt("", "TypeInfo.getId", 1, "config.Config"); id == ida || id == idb {
+		fmt.Println("ambiguous short name config.Config resolved to type id", id)
+	}
+}
@@ -0,0 +1,24 @@
+package main
+
+// no use of reflect, directly or through fmt, so most types only need minimal type information
+
+type shape interface {
+	area() int
+}
+
+type square struct{ side int }
+
+func (s square) area() int { return s.side * s.side }
+
+type grid struct {
+	cells [4][4]byte
+	names map[string][]int
+	next  *grid
+}
+
+func main() {
+	var s shape = square{3}
+	g := &grid{names: map[string][]int{"a": {1}}}
+	sq, ok := s.(square)
+	println(s.area(), sq.side, ok && g.next == nil)
+}
@@ -0,0 +1,94 @@
+package tgossa
+
+import "golang.org/x/tools/go/ssa"
+
+// RecursionUsesGR marks every function in a cycle of function references as using goroutines,
+// so that its calls can be made via the goroutine scheduler, rather than always on the host stack.
+// Then, as in visit.function(), every function that references one that uses goroutines is also marked.
+func RecursionUsesGR(seen, usesGR map[*ssa.Function]bool, isOvl isOverloaded) {
+	calls := make(map[*ssa.Function][]*ssa.Function)
+	for fn := range seen {
+		if isOvl != nil && isOvl(fn) {
+			continue
+		}
+		var buf [10]*ssa.Value // avoid alloc in common case
+		for _, b := range fn.Blocks {
+			for _, instr := range b.Instrs {
+				for _, op := range instr.Operands(buf[:0]) {
+					if afn, isFn := (*op).(*ssa.Function); isFn && seen[afn] {
+						calls[fn] = append(calls[fn], afn)
+					}
+				}
+			}
+		}
+	}
+
+	// Tarjan's strongly connected components algorithm, a component of more than one function,
+	// or a function that refers to itself, is recursive.
+	index := make(map[*ssa.Function]int)
+	lowLink := make(map[*ssa.Function]int)
+	onStack := make(map[*ssa.Function]bool)
+	stack := []*ssa.Function{}
+	var strongConnect func(fn *ssa.Function)
+	strongConnect = func(fn *ssa.Function) {
+		index[fn] = len(index)
+		lowLink[fn] = index[fn]
+		stack = append(stack, fn)
+		onStack[fn] = true
+		for _, afn := range calls[fn] {
+			if _, visited := index[afn]; !visited {
+				strongConnect(afn)
+				if lowLink[afn] < lowLink[fn] {
+					lowLink[fn] = lowLink[afn]
+				}
+			} else if onStack[afn] && index[afn] < lowLink[fn] {
+				lowLink[fn] = index[afn]
+			}
+		}
+		if lowLink[fn] == index[fn] {
+			component := []*ssa.Function{}
+			for {
+				top := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[top] = false
+				component = append(component, top)
+				if top == fn {
+					break
+				}
+			}
+			recursive := len(component) > 1
+			for _, afn := range calls[fn] {
+				if afn == fn {
+					recursive = true
+				}
+			}
+			if recursive {
+				for _, rfn := range component {
+					vprintln("usesGR because recursive", rfn.Name())
+					usesGR[rfn] = true
+				}
+			}
+		}
+	}
+	for fn := range calls {
+		if _, visited := index[fn]; !visited {
+			strongConnect(fn)
+		}
+	}
+
+	for changed := true; changed; {
+		changed = false
+		for fn, afns := range calls {
+			if !usesGR[fn] {
+				for _, afn := range afns {
+					if usesGR[afn] {
+						vprintln("marked as using GR because referenced func uses GR", fn.Name())
+						usesGR[fn] = true
+						changed = true
+						break
+					}
+				}
+			}
+		}
+	}
+}